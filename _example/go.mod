@@ -5,3 +5,5 @@ go 1.18
 replace github.com/StevenACoffman/simplerr => ../
 
 require github.com/StevenACoffman/simplerr v0.0.0-00010101000000-000000000000
+
+require golang.org/x/xerrors v0.0.0-20240903120638-7835f813f4da // indirect
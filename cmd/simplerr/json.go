@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"runtime"
+
+	"github.com/StevenACoffman/simplerr/errors"
+	"github.com/StevenACoffman/simplerr/errors/errparse"
+)
+
+// jsonDump is the JSON schema of a dump, as written by the json command.
+type jsonDump struct {
+	Source      string `json:"source"`
+	Line        int    `json:"line"`
+	Fingerprint string `json:"fingerprint"`
+	jsonReport
+}
+
+type jsonReport struct {
+	Message     string      `json:"message"`
+	Layers      []jsonLayer `json:"layers"`
+	ReturnTrace []jsonFrame `json:"return_trace,omitempty"`
+	Hints       []string    `json:"hints,omitempty"`
+	Details     []string    `json:"details,omitempty"`
+}
+
+type jsonLayer struct {
+	Type         string      `json:"type,omitempty"`
+	Message      string      `json:"message"`
+	Frames       []jsonFrame `json:"frames,omitempty"`
+	ElidedFrames bool        `json:"elided_frames,omitempty"`
+	Masked       *jsonReport `json:"masked,omitempty"`
+	SpawnedFrom  []jsonFrame `json:"spawned_from,omitempty"`
+	Detail       []string    `json:"detail,omitempty"`
+}
+
+type jsonFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+}
+
+func toJSON(w io.Writer, dumps []source) error {
+	enc := json.NewEncoder(w)
+	for _, dump := range dumps {
+		if err := enc.Encode(jsonDump{
+			Source:      dump.Name,
+			Line:        dump.Line,
+			Fingerprint: errparse.Fingerprint(dump.Report),
+			jsonReport:  newJSONReport(dump.Report),
+		}); err != nil {
+			return errors.Wrap(err, "encoding dump")
+		}
+	}
+
+	return nil
+}
+
+func newJSONReport(report *errors.Report) jsonReport {
+	r := jsonReport{
+		Message: report.Message,
+		Hints:   report.Hints,
+		Details: report.Details,
+		Layers:  make([]jsonLayer, len(report.Layers)),
+	}
+	r.ReturnTrace = newJSONFrames(report.ReturnTrace)
+	for i, layer := range report.Layers {
+		l := jsonLayer{
+			Type:         layer.Type,
+			Message:      layer.Message,
+			ElidedFrames: layer.ElidedFrames,
+			Detail:       layer.Detail,
+		}
+		l.Frames = newJSONFrames(layer.Frames)
+		l.SpawnedFrom = newJSONFrames(layer.SpawnedFrom)
+		if layer.Masked != nil {
+			masked := newJSONReport(layer.Masked)
+			l.Masked = &masked
+		}
+		r.Layers[i] = l
+	}
+
+	return r
+}
+
+func newJSONFrames(frames []runtime.Frame) []jsonFrame {
+	var out []jsonFrame
+	for _, frame := range frames {
+		out = append(out, jsonFrame{Function: frame.Function, File: frame.File, Line: frame.Line})
+	}
+
+	return out
+}
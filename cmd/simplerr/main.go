@@ -0,0 +1,275 @@
+// Command simplerr extracts the error dumps from logs and helps triage
+// them. It understands the verbose (%+v) rendering of this module's errors
+// and Go goroutine dumps, such as those printed by unrecovered panics.
+//
+// Usage:
+//
+//	simplerr <command> [flags] [file ...]
+//
+// The commands are:
+//
+//	pretty     re-render each dump, with colors when writing to a terminal
+//	group      cluster the dumps by fingerprint, most frequent first
+//	json       convert each dump to a JSON object, one per line
+//	filter     re-render each dump without the frames of the given packages
+//	symbolize  expand the stacks encoded by errors.Stack.Encode, using the
+//	           symbol table of the binary that encoded them
+//
+// Logs are read from the named files, or from standard input if there are
+// none.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/StevenACoffman/simplerr/errors"
+	"github.com/StevenACoffman/simplerr/errors/errparse"
+)
+
+const usage = `usage: simplerr <command> [flags] [file ...]
+
+commands:
+  pretty     re-render each dump, with colors when writing to a terminal
+  group      cluster the dumps by fingerprint, most frequent first
+  json       convert each dump to a JSON object, one per line
+  filter     re-render each dump without the frames of the given packages
+  symbolize  expand encoded stacks, given the binary that encoded them
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command line args and returns the exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		_, _ = io.WriteString(stderr, usage)
+		return 2
+	}
+
+	flags := flag.NewFlagSet("simplerr "+args[0], flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var cmd func(w io.Writer, dumps []source) error
+	// copyCmd, if set, processes the inputs as text rather than dumps.
+	var copyCmd func(w io.Writer, r io.Reader) error
+	switch args[0] {
+	case "pretty":
+		color := flags.String("color", "auto", "colorize the output: auto, always or never")
+		cmd = func(w io.Writer, dumps []source) error {
+			return pretty(w, dumps, useColor(*color, w))
+		}
+	case "group":
+		cmd = group
+	case "json":
+		cmd = toJSON
+	case "filter":
+		var drop prefixes
+		flags.Var(&drop, "drop", "drop the frames of functions with this `prefix`, such as runtime. (repeatable)")
+		cmd = func(w io.Writer, dumps []source) error {
+			return filter(w, dumps, drop)
+		}
+	case "symbolize":
+		binary := flags.String("binary", "", "the `path` of the binary that encoded the stacks (required)")
+		copyCmd = func(w io.Writer, r io.Reader) error {
+			return symbolize(w, r, *binary)
+		}
+	case "help", "-h", "-help", "--help":
+		_, _ = io.WriteString(stdout, usage)
+		return 0
+	default:
+		_, _ = fmt.Fprintf(stderr, "simplerr: unknown command %q\n%s", args[0], usage)
+		return 2
+	}
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	out := bufio.NewWriter(stdout)
+	var err error
+	if copyCmd != nil {
+		err = copyInputs(out, flags.Args(), stdin, copyCmd)
+	} else {
+		var dumps []source
+		dumps, err = extract(flags.Args(), stdin)
+		if err == nil {
+			err = cmd(out, dumps)
+		}
+	}
+	if flushErr := out.Flush(); err == nil {
+		err = flushErr
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "simplerr: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+// source is a dump along with the name of the input it was found in.
+type source struct {
+	Name string
+	errparse.Dump
+}
+
+// extract returns the dumps found in the named files, or in stdin if no
+// file is named.
+func extract(names []string, stdin io.Reader) ([]source, error) {
+	if len(names) == 0 {
+		return extractFrom("-", stdin)
+	}
+	var all []source
+	for _, name := range names {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		dumps, err := extractFrom(name, f)
+		_ = f.Close()
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, dumps...)
+	}
+
+	return all, nil
+}
+
+// copyInputs applies copyCmd to the named files, or to stdin if no file is
+// named.
+func copyInputs(w io.Writer, names []string, stdin io.Reader, copyCmd func(io.Writer, io.Reader) error) error {
+	if len(names) == 0 {
+		return copyCmd(w, stdin)
+	}
+	for _, name := range names {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		err = copyCmd(w, f)
+		_ = f.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func extractFrom(name string, r io.Reader) ([]source, error) {
+	dumps, err := errparse.Extract(r)
+	if err != nil {
+		return nil, errors.WrapWithFields(err, errors.Fields{"input": name})
+	}
+	sources := make([]source, len(dumps))
+	for i, dump := range dumps {
+		sources[i] = source{Name: name, Dump: dump}
+	}
+
+	return sources, nil
+}
+
+// useColor resolves the -color flag of the pretty command.
+func useColor(mode string, w io.Writer) bool {
+	switch mode {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+
+	return errors.ColorAllowed(w)
+}
+
+func pretty(w io.Writer, dumps []source, color bool) error {
+	for _, dump := range dumps {
+		_, _ = fmt.Fprintf(w, "==> %s:%d <==\n", dump.Name, dump.Line)
+		errors.VerboseFormatter{Color: color}.Render(w, dump.Report)
+		_, _ = io.WriteString(w, "\n\n")
+	}
+
+	return nil
+}
+
+func group(w io.Writer, dumps []source) error {
+	type cluster struct {
+		fingerprint string
+		first       source
+		count       int
+	}
+	var clusters []*cluster
+	byFingerprint := map[string]*cluster{}
+	for _, dump := range dumps {
+		fingerprint := errparse.Fingerprint(dump.Report)
+		c, ok := byFingerprint[fingerprint]
+		if !ok {
+			c = &cluster{fingerprint: fingerprint, first: dump}
+			byFingerprint[fingerprint] = c
+			clusters = append(clusters, c)
+		}
+		c.count++
+	}
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return clusters[i].count > clusters[j].count
+	})
+	for _, c := range clusters {
+		msg, _, _ := strings.Cut(c.first.Report.Message, "\n")
+		_, _ = fmt.Fprintf(w, "%6d  %s  %s:%d  %s\n", c.count, c.fingerprint, c.first.Name, c.first.Line, msg)
+	}
+
+	return nil
+}
+
+// prefixes is a repeatable flag of function name prefixes.
+type prefixes []string
+
+func (p *prefixes) String() string { return strings.Join(*p, ",") }
+
+func (p *prefixes) Set(prefix string) error {
+	*p = append(*p, prefix)
+	return nil
+}
+
+func filter(w io.Writer, dumps []source, drop prefixes) error {
+	for _, dump := range dumps {
+		dropFrames(dump.Report, drop)
+		errors.VerboseFormatter{}.Render(w, dump.Report)
+		_, _ = io.WriteString(w, "\n")
+	}
+
+	return nil
+}
+
+// dropFrames removes the frames of the functions that start with one of
+// the prefixes from report, including the reports hidden behind barriers.
+func dropFrames(report *errors.Report, drop prefixes) {
+	for i := range report.Layers {
+		layer := &report.Layers[i]
+		kept := layer.Frames[:0]
+		for _, frame := range layer.Frames {
+			if !hasAnyPrefix(frame.Function, drop) {
+				kept = append(kept, frame)
+			}
+		}
+		layer.Frames = kept
+		if layer.Masked != nil {
+			dropFrames(layer.Masked, drop)
+		}
+	}
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/StevenACoffman/simplerr/errors"
+)
+
+func testLog() string {
+	var lines []string
+	for i := 0; i < 3; i++ {
+		lines = append(lines, "level=error msg="+errors.FormatWith(errors.New("disk full"), errors.VerboseFormatter{}))
+	}
+	lines = append(lines, `panic: boom
+
+goroutine 1 [running]:
+main.main()
+	/app/main.go:5 +0x25
+exit status 2`)
+
+	return strings.Join(lines, "\n")
+}
+
+func runTest(t *testing.T, args ...string) string {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	if code := run(args, strings.NewReader(testLog()), &stdout, &stderr); code != 0 {
+		t.Fatalf("%v: exit code %d: %s", args, code, stderr.String())
+	}
+
+	return stdout.String()
+}
+
+func TestPretty(t *testing.T) {
+	out := runTest(t, "pretty", "-color", "always")
+	if strings.Count(out, "==> -:") != 4 {
+		t.Fatalf("expected 4 dumps in:\n%s", out)
+	}
+	if !strings.Contains(out, "\x1b[") {
+		t.Fatalf("expected colors in:\n%s", out)
+	}
+	if out := runTest(t, "pretty"); strings.Contains(out, "\x1b[") {
+		t.Fatalf("unexpected colors in:\n%s", out)
+	}
+}
+
+func TestGroup(t *testing.T) {
+	lines := strings.Split(strings.TrimSpace(runTest(t, "group")), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 groups, got:\n%s", strings.Join(lines, "\n"))
+	}
+	if fields := strings.Fields(lines[0]); fields[0] != "3" || !strings.HasSuffix(lines[0], "disk full") {
+		t.Errorf("unexpected first group %q", lines[0])
+	}
+	if fields := strings.Fields(lines[1]); fields[0] != "1" || !strings.HasSuffix(lines[1], "boom") {
+		t.Errorf("unexpected second group %q", lines[1])
+	}
+}
+
+func TestJSON(t *testing.T) {
+	lines := strings.Split(strings.TrimSpace(runTest(t, "json")), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 dumps, got %d", len(lines))
+	}
+	var dump jsonDump
+	if err := json.Unmarshal([]byte(lines[3]), &dump); err != nil {
+		t.Fatal(err)
+	}
+	if dump.Message != "boom" || dump.Source != "-" || len(dump.Layers) != 1 ||
+		dump.Layers[0].Frames[0] != (jsonFrame{Function: "main.main", File: "/app/main.go", Line: 5}) {
+		t.Fatalf("unexpected dump %+v", dump)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	out := runTest(t, "filter", "-drop", "testing.", "-drop", "main.")
+	if strings.Contains(out, "testing.tRunner") || strings.Contains(out, "main.main") {
+		t.Fatalf("expected frames to be dropped:\n%s", out)
+	}
+	if !strings.Contains(out, "TestFilter") && !strings.Contains(out, "testLog") {
+		t.Fatalf("expected other frames to be kept:\n%s", out)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"bogus"}, strings.NewReader(""), &stdout, &stderr); code != 2 {
+		t.Fatalf("expected exit code 2, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), `unknown command "bogus"`) {
+		t.Fatalf("unexpected stderr %q", stderr.String())
+	}
+}
+
+func TestSymbolize(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("symbolization needs an ELF binary")
+	}
+	binary, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded := errors.GetStack(errors.New("boom")).Encode()
+
+	var stdout, stderr bytes.Buffer
+	log := "level=error stack=" + encoded + "\nlevel=info\n"
+	if code := run([]string{"symbolize", "-binary", binary}, strings.NewReader(log), &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr.String())
+	}
+	if !strings.HasPrefix(stdout.String(), "level=error stack=github.com/StevenACoffman/simplerr/cmd/simplerr.TestSymbolize\n\t") ||
+		!strings.HasSuffix(stdout.String(), "\nlevel=info\n") {
+		t.Fatalf("unexpected output:\n%s", stdout.String())
+	}
+
+	stderr.Reset()
+	if code := run([]string{"symbolize"}, strings.NewReader(log), &stdout, &stderr); code != 1 {
+		t.Fatalf("expected exit code 1 without -binary, got %d", code)
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/StevenACoffman/simplerr/errors"
+	"github.com/StevenACoffman/simplerr/errors/errsym"
+)
+
+// symbolize copies r to w, expanding the encoded stacks it contains with
+// the symbol table of binary.
+func symbolize(w io.Writer, r io.Reader, binary string) error {
+	if binary == "" {
+		return errors.New("-binary is required")
+	}
+	s, err := errsym.Open(binary)
+	if err != nil {
+		return err
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if _, err := io.WriteString(w, s.Expand(scanner.Text())+"\n"); err != nil {
+			return err
+		}
+	}
+
+	return errors.Wrap(scanner.Err(), "reading input")
+}
@@ -0,0 +1,184 @@
+package compat_test
+
+import (
+	stderrs "errors"
+	"fmt"
+	"io"
+	"testing"
+
+	pkgerrors "github.com/pkg/errors"
+	"golang.org/x/xerrors"
+
+	"github.com/StevenACoffman/simplerr/errors"
+)
+
+type leafError struct{ msg string }
+
+func (e *leafError) Error() string { return e.msg }
+
+// divergingError has a Cause that skips ahead to the root cause, and an
+// Unwrap that returns the next error.
+type divergingError struct{ next, root error }
+
+func (e divergingError) Error() string { return "diverging: " + e.next.Error() }
+func (e divergingError) Cause() error  { return e.root }
+func (e divergingError) Unwrap() error { return e.next }
+
+// causeError has only Cause, as the errors of github.com/pkg/errors before
+// v0.9.
+type causeError struct{ cause error }
+
+func (e causeError) Error() string { return "cause: " + e.cause.Error() }
+func (e causeError) Cause() error  { return e.cause }
+
+var (
+	leaf   = &leafError{msg: "leaf"}
+	middle = fmt.Errorf("middle: %w", leaf)
+)
+
+type chain struct {
+	name string
+	err  error
+	// pkgErrorsCause is whether pkg/errors' Cause finds the same root
+	// cause as ours, that is whether every wrapper has a Cause method.
+	pkgErrorsCause bool
+}
+
+func chains() []chain {
+	return []chain{
+		{name: "leaf", err: leaf, pkgErrorsCause: true},
+		{name: "fmt", err: middle},
+		{name: "fmt multi", err: fmt.Errorf("%w and %w", io.EOF, middle)},
+		{name: "pkg/errors Wrap", err: pkgerrors.Wrap(leaf, "wrapped"), pkgErrorsCause: true},
+		{name: "pkg/errors WithMessage", err: pkgerrors.WithMessage(leaf, "message"), pkgErrorsCause: true},
+		{name: "pkg/errors WithStack of fmt", err: pkgerrors.WithStack(middle)},
+		{name: "xerrors Errorf", err: xerrors.Errorf("x: %w", leaf)},
+		{name: "xerrors Opaque", err: xerrors.Opaque(middle)},
+		{name: "WithStack", err: errors.WithStack(leaf), pkgErrorsCause: true},
+		{name: "WrapWithFields", err: errors.WrapWithFields(leaf, errors.Fields{"k": 1}), pkgErrorsCause: true},
+		{name: "Wrap", err: errors.Wrap(pkgerrors.Wrap(leaf, "inner"), "outer")},
+		{name: "With", err: errors.With(io.EOF, leaf)},
+		{name: "Trace", err: errors.Trace(errors.Trace(middle))},
+		{name: "mixed", err: errors.WithStack(pkgerrors.WithMessage(xerrors.Errorf("x: %w", errors.WithStack(leaf)), "m"))},
+		{name: "diverging", err: errors.WithStack(divergingError{next: middle, root: io.ErrUnexpectedEOF})},
+		{name: "cause only", err: pkgerrors.WithStack(causeError{cause: io.ErrUnexpectedEOF})},
+	}
+}
+
+var targets = []error{leaf, middle, io.EOF, io.ErrUnexpectedEOF}
+
+func withTraversalPolicy(t testing.TB, p errors.TraversalPolicy) {
+	prev := errors.SetTraversalPolicy(p)
+	t.Cleanup(func() { errors.SetTraversalPolicy(prev) })
+}
+
+// same reports whether a and b are the same error. The errors built with
+// With return a new error from each call to Unwrap, so they are compared
+// by type and message.
+func same(a, b error) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return fmt.Sprintf("%T", a) == fmt.Sprintf("%T", b) && a.Error() == b.Error()
+}
+
+// TestUnwrapOnly checks that, with UnwrapOnly, chains are traversed
+// exactly as by the standard library and xerrors.
+func TestUnwrapOnly(t *testing.T) {
+	withTraversalPolicy(t, errors.UnwrapOnly)
+	for _, c := range chains() {
+		t.Run(c.name, func(t *testing.T) {
+			for _, target := range targets {
+				expected := stderrs.Is(c.err, target)
+				if actual := errors.Is(c.err, target); actual != expected {
+					t.Errorf("Is(%v): expected %t as the standard library, got %t", target, expected, actual)
+				}
+				if actual := xerrors.Is(c.err, target); actual != expected {
+					t.Errorf("xerrors.Is(%v) = %t disagrees with the standard library", target, actual)
+				}
+			}
+
+			var stdTarget, target *leafError
+			if errors.As(c.err, &target) != stderrs.As(c.err, &stdTarget) || target != stdTarget {
+				t.Errorf("As: expected %v as the standard library, got %v", stdTarget, target)
+			}
+
+			for err := c.err; err != nil; err = stderrs.Unwrap(err) {
+				if actual, expected := errors.Unwrap(err), stderrs.Unwrap(err); !same(actual, expected) {
+					t.Errorf("Unwrap(%T): expected %v as the standard library, got %v", err, expected, actual)
+				}
+				if actual, expected := errors.Unwrap(err), xerrors.Unwrap(err); !same(actual, expected) {
+					t.Errorf("Unwrap(%T): expected %v as xerrors, got %v", err, expected, actual)
+				}
+			}
+		})
+	}
+}
+
+// TestPreferCause checks that, with the default policy, root causes are
+// the ones of pkg/errors for chains that pkg/errors can traverse, and that
+// Is agrees with the standard library unless Cause and Unwrap diverge.
+func TestPreferCause(t *testing.T) {
+	withTraversalPolicy(t, errors.PreferCause)
+	for _, c := range chains() {
+		t.Run(c.name, func(t *testing.T) {
+			if c.pkgErrorsCause {
+				if actual, expected := errors.Cause(c.err), pkgerrors.Cause(c.err); actual != expected {
+					t.Errorf("Cause: expected %v as pkg/errors, got %v", expected, actual)
+				}
+			}
+			if c.name == "diverging" || c.name == "cause only" {
+				return
+			}
+			for _, target := range targets {
+				if actual, expected := errors.Is(c.err, target), stderrs.Is(c.err, target); actual != expected {
+					t.Errorf("Is(%v): expected %t as the standard library, got %t", target, expected, actual)
+				}
+			}
+		})
+	}
+}
+
+// TestDivergingChains checks the chains on which the policies disagree.
+func TestDivergingChains(t *testing.T) {
+	diverging := errors.WithStack(divergingError{next: middle, root: io.ErrUnexpectedEOF})
+	causeOnly := pkgerrors.WithStack(causeError{cause: io.ErrUnexpectedEOF})
+	tests := []struct {
+		name   string
+		policy errors.TraversalPolicy
+		// The expected results of Is(diverging, leaf), Is(diverging,
+		// io.ErrUnexpectedEOF) and Is(causeOnly, io.ErrUnexpectedEOF).
+		leaf, root, causeOnly bool
+	}{
+		{name: "PreferCause", policy: errors.PreferCause, root: true, causeOnly: true},
+		{name: "PreferUnwrap", policy: errors.PreferUnwrap, leaf: true, causeOnly: true},
+		{name: "UnwrapOnly", policy: errors.UnwrapOnly, leaf: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withTraversalPolicy(t, tt.policy)
+			if actual := errors.Is(diverging, leaf); actual != tt.leaf {
+				t.Errorf("Is(diverging, leaf): expected %t, got %t", tt.leaf, actual)
+			}
+			if actual := errors.Is(diverging, io.ErrUnexpectedEOF); actual != tt.root {
+				t.Errorf("Is(diverging, root): expected %t, got %t", tt.root, actual)
+			}
+			if actual := errors.Is(causeOnly, io.ErrUnexpectedEOF); actual != tt.causeOnly {
+				t.Errorf("Is(causeOnly, root): expected %t, got %t", tt.causeOnly, actual)
+			}
+			// Walk and UnwrapAll follow the same path as Is.
+			var walked []error
+			errors.Walk(diverging, func(e error, _ int, _ []int) bool {
+				walked = append(walked, e)
+				return true
+			})
+			if actual := walked[len(walked)-1]; actual != errors.UnwrapAll(diverging) {
+				t.Errorf("Walk ends at %v, but UnwrapAll returns %v", actual, errors.UnwrapAll(diverging))
+			}
+		})
+	}
+	// The standard library ignores Cause.
+	if stderrs.Is(causeOnly, io.ErrUnexpectedEOF) || !stderrs.Is(diverging, leaf) {
+		t.Error("unexpected standard library behavior")
+	}
+}
@@ -0,0 +1,5 @@
+// Package compat cross-checks the traversal of error chains by
+// github.com/StevenACoffman/simplerr/errors against the standard library,
+// github.com/pkg/errors and golang.org/x/xerrors. It lives in its own
+// module, so that the errors package does not depend on pkg/errors.
+package compat
@@ -0,0 +1,11 @@
+module github.com/StevenACoffman/simplerr/compat
+
+go 1.20
+
+replace github.com/StevenACoffman/simplerr => ../
+
+require (
+	github.com/StevenACoffman/simplerr v0.0.0-00010101000000-000000000000
+	github.com/pkg/errors v0.9.1
+	golang.org/x/xerrors v0.0.0-20240903120638-7835f813f4da
+)
@@ -0,0 +1,17 @@
+// Command errlint runs the errlint analyzer, which reports misuses of
+// errors and of github.com/StevenACoffman/simplerr/errors. It can be run
+// on its own or by go vet:
+//
+//	errlint ./...
+//	go vet -vettool=$(which errlint) ./...
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/singlechecker"
+
+	"github.com/StevenACoffman/simplerr/errlint"
+)
+
+func main() {
+	singlechecker.Main(errlint.Analyzer)
+}
@@ -0,0 +1,404 @@
+// Package errlint defines an analyzer that reports misuses of the
+// github.com/StevenACoffman/simplerr/errors package, and of errors in
+// general:
+//
+//   - WithStack applied to an error that was just given a stack by the
+//     same call site, as in errors.WithStack(errors.New("boom"));
+//   - fmt.Errorf formatting an error with %v or %s, which loses its
+//     wrapping, instead of %w;
+//   - errors compared with == or != instead of errors.Is, which does not
+//     see through wrapping;
+//   - WrapWithFields with nil Fields, which only adds a stack;
+//   - package-level errors created with New, which captures the stack of
+//     the package initializer, instead of Sentinel.
+//
+// Most diagnostics come with a suggested fix. A diagnostic is suppressed
+// by an //errlint:ignore comment on its line, or on the line before, for
+// code that means what it does, such as a test checking that a function
+// returns a given error value, and not only one that wraps it:
+//
+//	if err != io.EOF { //errlint:ignore identity of the returned error
+//
+// The analyzer can be run by
+// go vet with the errlint command:
+//
+//	go install github.com/StevenACoffman/simplerr/errlint/cmd/errlint@latest
+//	go vet -vettool=$(which errlint) ./...
+package errlint
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// errorsPath is the import path of the package whose use is checked.
+const errorsPath = "github.com/StevenACoffman/simplerr/errors"
+
+// Analyzer reports misuses of errors.
+var Analyzer = &analysis.Analyzer{
+	Name:     "errlint",
+	Doc:      "report misuses of errors and of github.com/StevenACoffman/simplerr/errors",
+	URL:      "https://pkg.go.dev/github.com/StevenACoffman/simplerr/errlint",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// stackCapturing lists the functions of the errors package that return an
+// error with a stack captured at their call site.
+var stackCapturing = map[string]bool{
+	"New":                    true,
+	"Wrap":                   true,
+	"Wrapf":                  true,
+	"WithStack":              true,
+	"WithStackDepth":         true,
+	"WrapWithFields":         true,
+	"WrapWithFieldsAndDepth": true,
+	"Handled":                true,
+	"HandledWithMessage":     true,
+}
+
+var errorType = types.Universe.Lookup("error").Type()
+
+// ignoreDirective is the comment that suppresses the diagnostics of its
+// line, and of the next one.
+const ignoreDirective = "//errlint:ignore"
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	pass = ignoring(pass)
+	nodes := []ast.Node{(*ast.CallExpr)(nil), (*ast.BinaryExpr)(nil), (*ast.GenDecl)(nil)}
+	insp.WithStack(nodes, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+		switch n := n.(type) {
+		case *ast.CallExpr:
+			checkCall(pass, n)
+		case *ast.BinaryExpr:
+			checkComparison(pass, n, stack)
+		case *ast.GenDecl:
+			if _, ok := stack[len(stack)-2].(*ast.File); ok {
+				checkPackageVars(pass, n)
+			}
+		}
+		return true
+	})
+
+	return nil, nil
+}
+
+// ignoring returns a copy of pass that drops the diagnostics on the lines
+// of an ignore directive, and on the lines after them.
+func ignoring(pass *analysis.Pass) *analysis.Pass {
+	type line struct {
+		file string
+		line int
+	}
+	ignored := map[line]bool{}
+	for _, f := range pass.Files {
+		for _, group := range f.Comments {
+			for _, c := range group.List {
+				if c.Text != ignoreDirective && !strings.HasPrefix(c.Text, ignoreDirective+" ") {
+					continue
+				}
+				pos := pass.Fset.Position(c.Slash)
+				ignored[line{pos.Filename, pos.Line}] = true
+				ignored[line{pos.Filename, pos.Line + 1}] = true
+			}
+		}
+	}
+	if len(ignored) == 0 {
+		return pass
+	}
+
+	filtered := *pass
+	filtered.Report = func(d analysis.Diagnostic) {
+		pos := pass.Fset.Position(d.Pos)
+		if !ignored[line{pos.Filename, pos.Line}] {
+			pass.Report(d)
+		}
+	}
+
+	return &filtered
+}
+
+// errorsFunc returns the name of the function of the errors package call
+// calls, or "".
+func errorsFunc(pass *analysis.Pass, call *ast.CallExpr) string {
+	fn := typeutil.StaticCallee(pass.TypesInfo, call)
+	if fn == nil || fn.Pkg() == nil || fn.Pkg().Path() != errorsPath {
+		return ""
+	}
+
+	return fn.Name()
+}
+
+func checkCall(pass *analysis.Pass, call *ast.CallExpr) {
+	if fn := typeutil.StaticCallee(pass.TypesInfo, call); fn != nil && fn.Pkg() != nil &&
+		fn.Pkg().Path() == "fmt" && fn.Name() == "Errorf" {
+		checkErrorf(pass, call)
+		return
+	}
+
+	switch errorsFunc(pass, call) {
+	case "WithStack":
+		if len(call.Args) != 1 {
+			return
+		}
+		inner, ok := ast.Unparen(call.Args[0]).(*ast.CallExpr)
+		if !ok || !stackCapturing[errorsFunc(pass, inner)] {
+			return
+		}
+		pass.Report(analysis.Diagnostic{
+			Pos:     call.Pos(),
+			End:     call.End(),
+			Message: "WithStack of an error that already has a stack from this call site",
+			SuggestedFixes: []analysis.SuggestedFix{{
+				Message:   "Remove the redundant WithStack",
+				TextEdits: []analysis.TextEdit{replace(pass, call, call.Args[0])},
+			}},
+		})
+	case "WrapWithFields":
+		if len(call.Args) != 2 || !isNil(pass, call.Args[1]) {
+			return
+		}
+		fix := []analysis.SuggestedFix{}
+		if sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr); ok {
+			fix = append(fix, analysis.SuggestedFix{
+				Message: "Use WithStack",
+				TextEdits: []analysis.TextEdit{{
+					Pos:     sel.Sel.Pos(),
+					End:     call.Rparen,
+					NewText: []byte("WithStack(" + source(pass, call.Args[0])),
+				}},
+			})
+		}
+		pass.Report(analysis.Diagnostic{
+			Pos:            call.Pos(),
+			End:            call.End(),
+			Message:        "WrapWithFields with nil Fields adds no fields; use WithStack",
+			SuggestedFixes: fix,
+		})
+	}
+}
+
+// checkErrorf reports the errors formatted with %v or %s by fmt.Errorf.
+func checkErrorf(pass *analysis.Pass, call *ast.CallExpr) {
+	if len(call.Args) == 0 {
+		return
+	}
+	lit, ok := ast.Unparen(call.Args[0]).(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return
+	}
+	format, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return
+	}
+	// Offsets in format are offsets in the literal only without escapes.
+	editable := !strings.Contains(lit.Value, `\`)
+	for _, v := range parseVerbs(format) {
+		if v.verb != 'v' && v.verb != 's' || v.arg+1 >= len(call.Args) {
+			continue
+		}
+		arg := call.Args[v.arg+1]
+		if !types.Implements(pass.TypesInfo.TypeOf(arg), errorType.Underlying().(*types.Interface)) {
+			continue
+		}
+		d := analysis.Diagnostic{
+			Pos:     arg.Pos(),
+			End:     arg.End(),
+			Message: "fmt.Errorf formats " + source(pass, arg) + " with %" + string(v.verb) + ", which loses its wrapping; use %w",
+		}
+		if editable && !v.flags {
+			pos := lit.Pos() + 1 + token.Pos(v.offset)
+			d.SuggestedFixes = []analysis.SuggestedFix{{
+				Message:   "Wrap with %w",
+				TextEdits: []analysis.TextEdit{{Pos: pos, End: pos + 1, NewText: []byte("w")}},
+			}}
+		}
+		pass.Report(d)
+	}
+}
+
+// verb is a formatting verb of a format string.
+type verb struct {
+	verb   rune
+	offset int  // of the verb letter in the format
+	arg    int  // index of the argument, after the format
+	flags  bool // whether the verb has flags, a width or a precision
+}
+
+// parseVerbs returns the verbs of a fmt format string that consume an
+// argument, with the index of the argument they format.
+func parseVerbs(format string) []verb {
+	var verbs []verb
+	arg := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		v := verb{}
+		// Skip the flags, argument indexes, width and precision.
+		for i++; i < len(format); i++ {
+			c := format[i]
+			if c == '*' {
+				arg++
+			} else if c == '[' {
+				end := strings.IndexByte(format[i:], ']')
+				if end < 0 {
+					return verbs
+				}
+				n, err := strconv.Atoi(format[i+1 : i+end])
+				if err != nil {
+					return verbs
+				}
+				arg = n - 1
+				i += end
+				continue
+			} else if strings.IndexByte("+-# 0123456789.", c) < 0 {
+				break
+			}
+			v.flags = true
+		}
+		if i == len(format) {
+			break
+		}
+		if format[i] == '%' {
+			continue
+		}
+		v.verb, v.offset, v.arg = rune(format[i]), i, arg
+		verbs = append(verbs, v)
+		arg++
+	}
+
+	return verbs
+}
+
+// checkComparison reports errors compared with == or !=.
+func checkComparison(pass *analysis.Pass, expr *ast.BinaryExpr, stack []ast.Node) {
+	if expr.Op != token.EQL && expr.Op != token.NEQ {
+		return
+	}
+	if isNil(pass, expr.X) || isNil(pass, expr.Y) {
+		return
+	}
+	x, y := pass.TypesInfo.TypeOf(expr.X), pass.TypesInfo.TypeOf(expr.Y)
+	if !types.Identical(x, errorType) && !types.Identical(y, errorType) {
+		return
+	}
+	// Values such as the result of recover cannot be given to errors.Is.
+	if !types.AssignableTo(x, errorType) || !types.AssignableTo(y, errorType) {
+		return
+	}
+	// Is methods implement errors.Is for a single level, with ==.
+	for _, n := range stack {
+		if fn, ok := n.(*ast.FuncDecl); ok && fn.Name.Name == "Is" && fn.Recv != nil {
+			return
+		}
+	}
+
+	d := analysis.Diagnostic{
+		Pos:     expr.Pos(),
+		End:     expr.End(),
+		Message: "comparing errors with " + expr.Op.String() + " does not see through wrapping; use errors.Is",
+	}
+	if name := errorsImportName(stack); name != "" {
+		text := name + ".Is(" + source(pass, expr.X) + ", " + source(pass, expr.Y) + ")"
+		if expr.Op == token.NEQ {
+			text = "!" + text
+		}
+		d.SuggestedFixes = []analysis.SuggestedFix{{
+			Message:   "Use errors.Is",
+			TextEdits: []analysis.TextEdit{{Pos: expr.Pos(), End: expr.End(), NewText: []byte(text)}},
+		}}
+	}
+	pass.Report(d)
+}
+
+// errorsImportName returns the name the file at the root of stack imports
+// the standard errors package or this errors package as, or "".
+func errorsImportName(stack []ast.Node) string {
+	file, ok := stack[0].(*ast.File)
+	if !ok {
+		return ""
+	}
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		if path != "errors" && path != errorsPath {
+			continue
+		}
+		if spec.Name == nil {
+			return "errors"
+		}
+		if spec.Name.Name != "_" && spec.Name.Name != "." {
+			return spec.Name.Name
+		}
+	}
+
+	return ""
+}
+
+// checkPackageVars reports package-level errors created with New.
+func checkPackageVars(pass *analysis.Pass, decl *ast.GenDecl) {
+	if decl.Tok != token.VAR {
+		return
+	}
+	for _, spec := range decl.Specs {
+		for _, value := range spec.(*ast.ValueSpec).Values {
+			call, ok := ast.Unparen(value).(*ast.CallExpr)
+			if !ok || errorsFunc(pass, call) != "New" {
+				continue
+			}
+			d := analysis.Diagnostic{
+				Pos:     call.Pos(),
+				End:     call.End(),
+				Message: "package-level error created with New captures the stack of the package initializer; use Sentinel",
+			}
+			if sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr); ok {
+				d.SuggestedFixes = []analysis.SuggestedFix{{
+					Message:   "Use Sentinel",
+					TextEdits: []analysis.TextEdit{{Pos: sel.Sel.Pos(), End: sel.Sel.End(), NewText: []byte("Sentinel")}},
+				}}
+			}
+			pass.Report(d)
+		}
+	}
+}
+
+func isNil(pass *analysis.Pass, expr ast.Expr) bool {
+	return pass.TypesInfo.Types[expr].IsNil()
+}
+
+// source returns the source text of node.
+func source(pass *analysis.Pass, node ast.Node) string {
+	file := pass.Fset.File(node.Pos())
+	if file == nil {
+		return ""
+	}
+	for _, f := range pass.Files {
+		if pass.Fset.File(f.Pos()) != file {
+			continue
+		}
+		content, err := pass.ReadFile(file.Name())
+		if err != nil {
+			return ""
+		}
+		return string(content[file.Offset(node.Pos()):file.Offset(node.End())])
+	}
+
+	return ""
+}
+
+// replace returns the edit replacing old with the source of new.
+func replace(pass *analysis.Pass, old, new ast.Node) analysis.TextEdit {
+	return analysis.TextEdit{Pos: old.Pos(), End: old.End(), NewText: []byte(source(pass, new))}
+}
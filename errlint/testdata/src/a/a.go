@@ -0,0 +1,76 @@
+package a
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/StevenACoffman/simplerr/errors"
+)
+
+var ErrNotFound = errors.New("not found") // want `package-level error created with New captures the stack of the package initializer; use Sentinel`
+
+var errPlain = fmt.Errorf("plain")
+
+func load() error {
+	return errors.New("boom")
+}
+
+func redundantStack() error {
+	if true {
+		return errors.WithStack(errors.New("boom")) // want `WithStack of an error that already has a stack from this call site`
+	}
+	if true {
+		return errors.WithStack(errors.Wrap(io.EOF, "reading")) // want `WithStack of an error that already has a stack from this call site`
+	}
+	return errors.WithStack(load())
+}
+
+func formatting(err error) error {
+	if true {
+		return fmt.Errorf("loading: %v", err) // want `fmt.Errorf formats err with %v, which loses its wrapping; use %w`
+	}
+	if true {
+		return fmt.Errorf("%d attempts: %s", 3, err) // want `fmt.Errorf formats err with %s, which loses its wrapping; use %w`
+	}
+	if true {
+		return fmt.Errorf("%[2]s: %[1]v", err, "ctx") // want `fmt.Errorf formats err with %v, which loses its wrapping; use %w`
+	}
+	if true {
+		return fmt.Errorf("loading: %10v", err) // want `fmt.Errorf formats err with %v, which loses its wrapping; use %w`
+	}
+	return fmt.Errorf("loading %s: %w", "config", err)
+}
+
+func comparing(err error) bool {
+	if err == io.EOF { // want `comparing errors with == does not see through wrapping; use errors.Is`
+		return true
+	}
+	if err != ErrNotFound { // want `comparing errors with != does not see through wrapping; use errors.Is`
+		return false
+	}
+	return err == nil
+}
+
+type notFound struct{}
+
+func (notFound) Error() string { return "not found" }
+
+func (e notFound) Is(target error) bool {
+	return target == error(e)
+}
+
+func fields(err error) error {
+	if true {
+		return errors.WrapWithFields(err, nil) // want `WrapWithFields with nil Fields adds no fields; use WithStack`
+	}
+	return errors.WrapWithFields(err, errors.Fields{"k": "v"})
+}
+
+func recovering() (err error) {
+	defer func() {
+		if p := recover(); p != nil && p != io.EOF {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,19 @@
+package a
+
+import (
+	"io"
+	"testing"
+)
+
+func TestIdentity(t *testing.T) {
+	if err := load(); err == io.EOF { // want `comparing errors with == does not see through wrapping; use errors.Is`
+		t.Fatal(err)
+	}
+	if err := load(); err == io.EOF { //errlint:ignore load returns io.EOF itself
+		t.Fatal(err)
+	}
+	//errlint:ignore
+	if err := load(); err != io.EOF {
+		t.Fatal(err)
+	}
+}
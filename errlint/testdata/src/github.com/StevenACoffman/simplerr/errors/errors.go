@@ -0,0 +1,13 @@
+// Package errors is a stub of github.com/StevenACoffman/simplerr/errors
+// for the tests of the analyzer.
+package errors
+
+type Fields map[string]interface{}
+
+func New(msg string) error                          { return nil }
+func Sentinel(msg string) error                     { return nil }
+func Wrap(err error, msg string) error              { return err }
+func WithStack(err error) error                     { return err }
+func WrapWithFields(err error, fields Fields) error { return err }
+func With(back, front error) error                  { return back }
+func Is(err, target error) bool                     { return err == target }
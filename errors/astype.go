@@ -0,0 +1,56 @@
+package errors
+
+// AsType finds the first error in err's chain that has type T, and
+// returns it. It is a type-safe alternative to As that cannot panic on
+// API misuse, and that does not need reflection unless an error in the
+// chain has an As(any) bool method.
+//
+// The chain is visited in the same order as Walk, so the front of an error
+// built with With is searched before its back, and every branch of a
+// multi-error is searched. An error matches if it has type T, or if it has
+// a method As(any) bool such that As(&t) returns true for a variable t of
+// type T. The As methods of this package's own wrapper types only
+// delegate to their causes, which are visited anyway, so they are
+// skipped.
+func AsType[T error](err error) (T, bool) {
+	for err != nil {
+		if t, ok := err.(T); ok {
+			return t, true
+		}
+
+		switch e := err.(type) {
+		case *wrapper:
+			if t, ok := AsType[T](e.front); ok {
+				return t, true
+			}
+			err = e.back
+			continue
+		case *withStack, *withFields:
+		case interface{ As(any) bool }:
+			var t T
+			if e.As(&t) {
+				return t, true
+			}
+		}
+
+		if e, ok := err.(interface{ Unwrap() []error }); ok && UnwrapOnce(err) == nil {
+			for _, cause := range e.Unwrap() {
+				if t, ok := AsType[T](cause); ok {
+					return t, true
+				}
+			}
+			break
+		}
+		err = UnwrapOnce(err)
+	}
+
+	var zero T
+	return zero, false
+}
+
+// Has reports whether err's chain contains an error of type T, as found
+// by AsType.
+func Has[T error](err error) bool {
+	_, ok := AsType[T](err)
+	return ok
+}
@@ -0,0 +1,91 @@
+package errors_test
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/StevenACoffman/simplerr/errors"
+)
+
+// asOtherError implements As to convert itself into an otherError.
+type asOtherError struct{}
+
+func (asOtherError) Error() string { return "convertible" }
+
+func (asOtherError) As(target any) bool {
+	if o, ok := target.(*otherError); ok {
+		*o = otherError{msg: "converted"}
+		return true
+	}
+	return false
+}
+
+func TestAsType(t *testing.T) {
+	err := errors.WrapWithFields(
+		errors.With(
+			errors.WithStack(myError("back")),
+			fmt.Errorf("front: %w", errors.New("inner")),
+		),
+		errors.Fields{"key": "value"},
+	)
+
+	my, ok := errors.AsType[myError](err)
+	if !ok || my != "back" {
+		t.Fatalf("expected to find myError, got %q, %v", my, ok)
+	}
+	if errors.Has[otherError](err) {
+		t.Fatal("unexpectedly found otherError")
+	}
+	type unwrapError interface {
+		error
+		Unwrap() error
+	}
+	if !errors.Has[unwrapError](err) {
+		t.Fatal("expected an interface type to match")
+	}
+
+	o, ok := errors.AsType[otherError](errors.Wrap(asOtherError{}, "context"))
+	if !ok || o.msg != "converted" {
+		t.Fatalf("expected the As method to be honored, got %v, %v", o, ok)
+	}
+
+	multi := fmt.Errorf("%w and %w", io.EOF, errors.WithStack(myError("multi")))
+	if my, ok := errors.AsType[myError](multi); !ok || my != "multi" {
+		t.Fatalf("expected to find myError in a multi-error, got %q, %v", my, ok)
+	}
+	if _, ok := errors.AsType[myError](nil); ok {
+		t.Fatal("unexpectedly found myError in nil")
+	}
+}
+
+func benchmarkChain() error {
+	err := errors.WithStack(myError("leaf"))
+	err = errors.WrapWithFields(err, errors.Fields{"key": "value"})
+	err = fmt.Errorf("context: %w", err)
+	err = errors.With(err, io.EOF)
+	return errors.WithStack(err)
+}
+
+func BenchmarkAsType(b *testing.B) {
+	err := benchmarkChain()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, ok := errors.AsType[myError](err); !ok {
+			b.Fatal("not found")
+		}
+	}
+}
+
+func BenchmarkAs(b *testing.B) {
+	err := benchmarkChain()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var my myError
+		if !errors.As(err, &my) {
+			b.Fatal("not found")
+		}
+	}
+}
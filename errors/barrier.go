@@ -0,0 +1,68 @@
+package errors
+
+import "fmt"
+
+// Barriers hide an error's causes from Is, As, UnwrapOnce and Cause,
+// while keeping its message and its verbose (%+v) rendering, stack traces
+// included. They are meant for API boundaries, where callers should not
+// be able to depend on the internals of the error they were handed.
+
+// Handled marks err as handled: the returned error has the same message
+// as err, but Is, As, UnwrapOnce and Cause do not traverse into it. A
+// stack trace is recorded at the point Handled was called.
+// If err is nil, Handled returns nil.
+func Handled(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return WithStackDepth(&barrier{msg: err.Error(), masked: err}, 1)
+}
+
+// HandledWithMessage is like Handled, but replaces the message of err
+// with msg.
+func HandledWithMessage(err error, msg string) error {
+	if err == nil {
+		return nil
+	}
+
+	return WithStackDepth(&barrier{msg: msg, masked: err}, 1)
+}
+
+// Opaque returns an error with the same message as err that does not
+// expose err to Is, As, UnwrapOnce and Cause. Unlike Handled, no stack
+// trace is recorded; this mirrors xerrors.Opaque.
+// If err is nil, Opaque returns nil.
+func Opaque(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &barrier{msg: err.Error(), masked: err}
+}
+
+// barrier is a leaf error: it deliberately implements neither Cause nor
+// Unwrap.
+type barrier struct {
+	msg    string
+	masked error
+}
+
+// compiler enforced interface conformance checks
+var (
+	_ error          = (*barrier)(nil)
+	_ fmt.Formatter  = (*barrier)(nil)
+	_ fmt.GoStringer = (*barrier)(nil)
+)
+
+func (b *barrier) Error() string { return b.msg }
+
+// Format implements the fmt.Formatter interface.
+func (b *barrier) Format(st fmt.State, verb rune) {
+	formatError(st, verb, b)
+}
+
+// GoString implements the fmt.GoStringer interface, for %#v.
+func (b *barrier) GoString() string {
+	return fmt.Sprintf("&errors.barrier{msg:%q, masked:%#v}", b.msg, b.masked)
+}
@@ -0,0 +1,190 @@
+package errors
+
+import (
+	"runtime"
+	"sync/atomic"
+)
+
+// CaptureMode selects how much of the call stack New, WithStack and the
+// other constructors capture.
+type CaptureMode int
+
+const (
+	// CaptureFull captures the whole call stack. This is the default.
+	CaptureFull CaptureMode = iota
+	// CaptureTop captures only the N innermost frames.
+	CaptureTop
+	// CaptureCaller captures only the immediate caller, like xerrors.Frame.
+	CaptureCaller
+	// CaptureSampled captures the whole call stack of 1 in N errors, and
+	// nothing for the others.
+	CaptureSampled
+	// CaptureDisabled captures nothing. Errors are still wrapped, so they
+	// keep the same shape, but have no stack trace.
+	CaptureDisabled
+)
+
+// CapturePolicy controls the cost of stack capture. Capturing a full stack
+// dominates the cost of creating an error, which matters on hot paths
+// where most errors are handled and discarded. From the cheapest to the
+// most expensive, per error (see BenchmarkCapture):
+//
+//	CaptureDisabled, CaptureSampled (amortized), CaptureCaller,
+//	CaptureTop, CaptureFull
+type CapturePolicy struct {
+	Mode CaptureMode
+	// N is the number of frames for CaptureTop, and the sampling period
+	// for CaptureSampled. Values below 1 are treated as 1.
+	N int
+}
+
+var (
+	capturePolicy atomic.Value // CapturePolicy
+	sampleCount   uint64
+)
+
+// SetCapturePolicy sets the policy used by Callers, WithStackDepth and
+// every constructor that captures a stack, and returns the previous one.
+func SetCapturePolicy(p CapturePolicy) CapturePolicy {
+	prev := GetCapturePolicy()
+	capturePolicy.Store(p)
+
+	return prev
+}
+
+// GetCapturePolicy returns the policy set by SetCapturePolicy.
+func GetCapturePolicy() CapturePolicy {
+	p, _ := capturePolicy.Load().(CapturePolicy)
+	return p
+}
+
+// CallersWithPolicy is like Callers, with p instead of the global policy.
+// It returns nil when p captures nothing.
+func CallersWithPolicy(skip int, p CapturePolicy) *Stack {
+	st, _ := capture(skip+1, p)
+	return st
+}
+
+// WithStackDepthPolicy is like WithStackDepth, with p instead of the global
+// policy.
+func WithStackDepthPolicy(err error, depth int, p CapturePolicy) error {
+	return withStackDepth(err, depth+1, p)
+}
+
+// capture returns the stack from skip according to p, and whether it is
+// the whole stack. As for captureStacktrace, skip=0 identifies capture
+// itself. As displays drop the last frame, which is normally
+// runtime.goexit or runtime.main, truncated stacks keep one extra frame.
+func capture(skip int, p CapturePolicy) (st *Stack, full bool) {
+	n := p.N
+	if n < 1 {
+		n = 1
+	}
+	switch p.Mode {
+	case CaptureTop:
+		return captureTop(skip, n+1), false
+	case CaptureCaller:
+		return captureTop(skip, 2), false
+	case CaptureSampled:
+		if (atomic.AddUint64(&sampleCount, 1)-1)%uint64(n) != 0 {
+			return nil, false
+		}
+	case CaptureDisabled:
+		return nil, false
+	}
+	s := Stack(captureStacktrace(skip))
+
+	return &s, true
+}
+
+// incrementalFrames is how many frames captureRelative captures before
+// looking for the suffix shared with the previous stack.
+const incrementalFrames = 16
+
+// captureRelative is like capture, but elides the frames already in prev,
+// as ElideSharedStackSuffix does, without capturing the whole stack when
+// it can. As for capture, skip=0 identifies captureRelative itself.
+func captureRelative(skip int, p CapturePolicy, prev *Stack) (st *Stack, elided bool) {
+	if prev == nil || len(*prev) == 0 || p.Mode != CaptureFull {
+		st, full := capture(skip+1, p)
+		if !full {
+			// Truncated stacks do not end at the root of the goroutine, so
+			// their suffix cannot be compared with the one of another stack.
+			return st, false
+		}
+		return ElideSharedStackSuffix(prev, st)
+	}
+
+	var buf [incrementalFrames]uintptr
+	// As for capture, skip=0 identifies captureRelative itself.
+	n := runtime.Callers(skip+1, buf[:])
+	if n < len(buf) {
+		// This is the whole stack.
+		pcs := Stack(buf[:n])
+		st, elided = ElideSharedStackSuffix(prev, &pcs)
+		own := append(Stack(nil), (*st)...)
+		return &own, elided
+	}
+	// The captured frames only join prev if the rest of the stack is the
+	// rest of prev, up to the root: recursive functions repeat runs of
+	// frames, which may be found in prev on a divergent path.
+	if i, j := sharedFrom(buf[:n], *prev); i >= 0 && stackIs(skip+n, (*prev)[j+n-i:]) {
+		if i == 0 {
+			// Keep at least one entry.
+			i = 1
+		}
+		own := append(Stack(nil), buf[:i]...)
+		return &own, true
+	}
+	// The frames in common with prev are deeper than the captured ones.
+	full := Stack(captureStacktrace(skip))
+
+	return ElideSharedStackSuffix(prev, &full)
+}
+
+// sharedFrom returns the index i of the first entry of pcs from which all
+// of pcs is found in prev, at index j of prev, or -1, -1.
+func sharedFrom(pcs, prev []uintptr) (i, j int) {
+	for i := range pcs {
+		for j := range prev {
+			if prev[j] != pcs[i] || len(prev)-j < len(pcs)-i {
+				continue
+			}
+			if equalPCs(pcs[i:], prev[j:j+len(pcs)-i]) {
+				return i, j
+			}
+		}
+	}
+
+	return -1, -1
+}
+
+// stackIs reports whether the stack from skip, which identifies the caller
+// of stackIs when 0, is pcs up to the root of the goroutine.
+func stackIs(skip int, pcs []uintptr) bool {
+	buf := make([]uintptr, len(pcs)+1)
+	// +2 to skip runtime.Callers and stackIs.
+	n := runtime.Callers(skip+2, buf)
+
+	return n == len(pcs) && equalPCs(buf[:n], pcs)
+}
+
+func equalPCs(a, b []uintptr) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// captureTop captures at most n frames from skip, which identifies the
+// caller of captureTop when 0.
+func captureTop(skip, n int) *Stack {
+	pcs := make([]uintptr, n)
+	// +2 to skip runtime.Callers and captureTop.
+	s := Stack(pcs[:runtime.Callers(skip+2, pcs)])
+
+	return &s
+}
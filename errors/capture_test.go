@@ -0,0 +1,246 @@
+package errors_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/StevenACoffman/simplerr/errors"
+)
+
+func withCapturePolicy(t testing.TB, p errors.CapturePolicy) {
+	prev := errors.SetCapturePolicy(p)
+	t.Cleanup(func() { errors.SetCapturePolicy(prev) })
+}
+
+func TestCapturePolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy errors.CapturePolicy
+		frames int // displayed frames, or -1 for a whole stack
+	}{
+		{name: "full", policy: errors.CapturePolicy{Mode: errors.CaptureFull}, frames: -1},
+		{name: "top", policy: errors.CapturePolicy{Mode: errors.CaptureTop, N: 2}, frames: 2},
+		{name: "caller", policy: errors.CapturePolicy{Mode: errors.CaptureCaller}, frames: 1},
+		{name: "disabled", policy: errors.CapturePolicy{Mode: errors.CaptureDisabled}, frames: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withCapturePolicy(t, tt.policy)
+			for _, err := range []error{
+				errors.New("boom"),
+				errors.WrapWithFields(fmt.Errorf("boom"), errors.Fields{"k": "v"}),
+			} {
+				frames := errors.NewReport(err).Layers[0].Frames
+				switch {
+				case tt.frames < 0 && len(frames) < 2,
+					tt.frames >= 0 && len(frames) != tt.frames:
+					t.Fatalf("%T: unexpected frames %v", err, frames)
+				}
+				if len(frames) > 0 && !strings.HasSuffix(frames[0].Function, "TestCapturePolicy.func1") {
+					t.Fatalf("%T: expected the caller first, got %s", err, frames[0].Function)
+				}
+				if !strings.HasPrefix(fmt.Sprintf("%+v", err), "(1) ") {
+					t.Fatalf("%T: unexpected verbose output %+v", err, err)
+				}
+			}
+		})
+	}
+}
+
+func TestCaptureSampled(t *testing.T) {
+	withCapturePolicy(t, errors.CapturePolicy{Mode: errors.CaptureSampled, N: 3})
+	captured := 0
+	for i := 0; i < 9; i++ {
+		if errors.GetStack(errors.New("boom")) != nil {
+			captured++
+		}
+	}
+	if captured != 3 {
+		t.Fatalf("expected 3 stacks out of 9 errors, got %d", captured)
+	}
+}
+
+func TestCapturePerCall(t *testing.T) {
+	withCapturePolicy(t, errors.CapturePolicy{Mode: errors.CaptureDisabled})
+	if st := errors.Callers(0); st != nil {
+		t.Fatalf("expected no stack, got %v", st)
+	}
+	st := errors.CallersWithPolicy(1, errors.CapturePolicy{Mode: errors.CaptureCaller})
+	if st == nil || len(*st) != 2 {
+		t.Fatalf("expected the caller and one more frame, got %v", st)
+	}
+	err := errors.WithStackDepthPolicy(fmt.Errorf("boom"), 0, errors.CapturePolicy{Mode: errors.CaptureFull})
+	if frames := errors.NewReport(err).Layers[0].Frames; len(frames) < 2 ||
+		!strings.HasSuffix(frames[0].Function, "TestCapturePerCall") {
+		t.Fatalf("unexpected frames %v", frames)
+	}
+}
+
+func TestCaptureNilStack(t *testing.T) {
+	for _, policy := range []errors.CapturePolicy{
+		{Mode: errors.CaptureDisabled},
+		{Mode: errors.CaptureSampled, N: 1 << 30},
+	} {
+		withCapturePolicy(t, policy)
+		// The first sampled call captures a stack, the following ones do not.
+		_ = errors.New("boom")
+		err := errors.New("boom")
+		st, ok := err.(interface{ StackTrace() *errors.StackTrace })
+		if !ok {
+			t.Fatalf("%T: expected a StackTrace method", err)
+		}
+		if s := st.StackTrace().String(); s != "" {
+			t.Fatalf("mode %d: expected an empty stack trace, got %q", policy.Mode, s)
+		}
+		if s := fmt.Sprintf("%+v", err); !strings.Contains(s, "boom") {
+			t.Fatalf("mode %d: unexpected verbose output %q", policy.Mode, s)
+		}
+		if s := fmt.Sprintf("%+v %v", errors.Callers(0), errors.Callers(0)); s != "<nil> <nil>" {
+			t.Fatalf("mode %d: unexpected stack output %q", policy.Mode, s)
+		}
+	}
+}
+
+// BenchmarkCapture documents the cost of each capture mode, for an error
+// created 32 calls deep.
+func BenchmarkCapture(b *testing.B) {
+	for _, bm := range []struct {
+		name   string
+		policy errors.CapturePolicy
+	}{
+		{name: "full", policy: errors.CapturePolicy{Mode: errors.CaptureFull}},
+		{name: "top8", policy: errors.CapturePolicy{Mode: errors.CaptureTop, N: 8}},
+		{name: "caller", policy: errors.CapturePolicy{Mode: errors.CaptureCaller}},
+		{name: "sampled100", policy: errors.CapturePolicy{Mode: errors.CaptureSampled, N: 100}},
+		{name: "disabled", policy: errors.CapturePolicy{Mode: errors.CaptureDisabled}},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			withCapturePolicy(b, bm.policy)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = newAtDepth(32)
+			}
+		})
+	}
+}
+
+func newAtDepth(depth int) error {
+	if depth == 0 {
+		return errors.New("boom")
+	}
+	return newAtDepth(depth - 1)
+}
+
+// stackAtDepth creates an error depth calls deep, and adds a stack to it
+// on the way back, stackAt calls deep.
+func stackAtDepth(depth, stackAt int) error {
+	if depth == 0 {
+		return errors.New("boom")
+	}
+	err := stackAtDepth(depth-1, stackAt)
+	if depth == stackAt {
+		return errors.WithStack(err)
+	}
+	return err
+}
+
+// wrapAtDepth creates an error depth calls deep, and wraps it on the way
+// back, wrapAt calls deep.
+func wrapAtDepth(depth, wrapAt int) error {
+	if depth == 0 {
+		return errors.New("boom")
+	}
+	err := wrapAtDepth(depth-1, wrapAt)
+	if depth == wrapAt {
+		return errors.Wrap(err, "wrapped")
+	}
+	return err
+}
+
+func TestCaptureRelative(t *testing.T) {
+	for _, stackAt := range []int{1, 20, 39} {
+		report := errors.NewReport(stackAtDepth(40, stackAt))
+		outer := report.Layers[0]
+		if !outer.ElidedFrames || len(outer.Frames) != 1 ||
+			!strings.HasSuffix(outer.Frames[0].Function, "errors_test.stackAtDepth") {
+			t.Errorf("stackAt=%d: expected only the frame of the WithStack call, got %v", stackAt, outer.Frames)
+		}
+		if inner := report.Layers[len(report.Layers)-2]; inner.ElidedFrames || len(inner.Frames) < 40 {
+			t.Errorf("stackAt=%d: expected the whole stack of New, got %d frames", stackAt, len(inner.Frames))
+		}
+	}
+
+	st := errors.Stack{1, 2, 3, 4}
+	for _, tt := range []struct {
+		prev     errors.Stack
+		expected errors.Stack
+		elided   bool
+	}{
+		{prev: errors.Stack{9, 3, 4}, expected: errors.Stack{1, 2}, elided: true},
+		{prev: errors.Stack{9, 4}, expected: errors.Stack{1, 2, 3}, elided: true},
+		{prev: errors.Stack{9, 8}, expected: errors.Stack{1, 2, 3, 4}},
+		{prev: errors.Stack{0, 1, 2, 3, 4}, expected: errors.Stack{1}, elided: true},
+	} {
+		actual, elided := errors.ElideSharedStackSuffix(&tt.prev, &st)
+		if fmt.Sprint(*actual) != fmt.Sprint(tt.expected) || elided != tt.elided {
+			t.Errorf("prev=%v: expected %v, %t but got %v, %t", tt.prev, tt.expected, tt.elided, *actual, elided)
+		}
+	}
+}
+
+// initError is created by the package initializer, so its stack shares
+// only runtime.main with the stacks of the main goroutine.
+var initError = newAtDepth(0)
+
+func TestCaptureRelativeWholeStack(t *testing.T) {
+	// The stack of an elided layer is not compared with: the outer layer
+	// is elided against the whole stack of New instead.
+	err := errors.WithStack(stackAtDepth(1, 1))
+	if outer := errors.NewReport(err).Layers[0]; !outer.ElidedFrames || len(outer.Frames) != 1 {
+		t.Errorf("expected the outer stack to be elided against the inner one, got %v", outer.Frames)
+	}
+
+	// Truncated and init stacks are not compared with either, and the
+	// outer layer keeps its whole stack.
+	prev := errors.SetCapturePolicy(errors.CapturePolicy{Mode: errors.CaptureTop, N: 2})
+	truncated := newAtDepth(0)
+	errors.SetCapturePolicy(prev)
+	for _, inner := range []error{truncated, initError} {
+		outer := errors.NewReport(errors.WithStack(inner)).Layers[0]
+		if outer.ElidedFrames || len(outer.Frames) < 2 ||
+			!strings.HasSuffix(outer.Frames[0].Function, "TestCaptureRelativeWholeStack") {
+			t.Errorf("expected the whole stack of WithStack, got %v", outer.Frames)
+		}
+	}
+}
+
+// recurse calls fn depth calls deep, always from the same call site.
+func recurse(depth int, fn func() error) error {
+	if depth == 0 {
+		return fn()
+	}
+	return recurse(depth-1, fn)
+}
+
+func TestCaptureRelativeDivergent(t *testing.T) {
+	// The innermost frames of WithStack are found in the stack of New,
+	// which was captured on another path, and are not elided: the closure,
+	// 21 calls of recurse and the test are kept.
+	inner := recurse(30, func() error { return errors.New("boom") })
+	err := recurse(20, func() error { return errors.WithStack(inner) })
+	frames := errors.NewReport(err).Layers[0].Frames
+	if len(frames) != 23 ||
+		!strings.HasSuffix(frames[len(frames)-1].Function, "errors_test.TestCaptureRelativeDivergent") {
+		t.Errorf("expected the frames up to the divergent call, got %v", frames)
+	}
+}
+
+// BenchmarkWrapStacked measures wrapping an error that already has a
+// stack, 32 calls deep.
+func BenchmarkWrapStacked(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = wrapAtDepth(32, 16)
+	}
+}
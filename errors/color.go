@@ -0,0 +1,94 @@
+package errors
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+// ANSI escape codes used by VerboseFormatter when Color is set.
+const (
+	ansiReset     = "\x1b[0m"
+	ansiBold      = "\x1b[1m"
+	ansiDim       = "\x1b[2m"
+	ansiDimItalic = "\x1b[2;3m"
+	ansiGreen     = "\x1b[32m"
+	ansiYellow    = "\x1b[33m"
+	ansiMagenta   = "\x1b[35m"
+	ansiCyan      = "\x1b[36m"
+)
+
+// Pretty returns the verbose rendering of err decorated with ANSI colors,
+// as produced by VerboseFormatter{Color: true}. Colors are left out if the
+// NO_COLOR environment variable is set (see https://no-color.org).
+func Pretty(err error) string {
+	var buf bytes.Buffer
+	VerboseFormatter{Color: colorAllowed()}.Render(&buf, NewReport(err))
+
+	return buf.String()
+}
+
+// FprintPretty writes the verbose rendering of err to w, followed by a
+// newline. It is decorated with ANSI colors only if w is a terminal and
+// the NO_COLOR environment variable is not set.
+func FprintPretty(w io.Writer, err error) {
+	VerboseFormatter{Color: ColorAllowed(w)}.Render(w, NewReport(err))
+	_, _ = io.WriteString(w, "\n")
+}
+
+// ColorAllowed reports whether ANSI colors may be written to w: w is a
+// terminal and neither the NO_COLOR environment variable nor TERM=dumb
+// rule colors out.
+func ColorAllowed(w io.Writer) bool {
+	return colorAllowed() && isTerminal(w)
+}
+
+// colorAllowed reports whether the environment allows colored output.
+func colorAllowed() bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+
+	return os.Getenv("TERM") != "dumb"
+}
+
+// isTerminal reports whether w is a character device, such as a TTY.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+var (
+	mainModuleOnce sync.Once
+	mainModule     string
+)
+
+// isAppFrame reports whether frame belongs to the main package or to the
+// main module of the running binary, as opposed to a dependency or the
+// standard library.
+func isAppFrame(frame runtime.Frame) bool {
+	if strings.HasPrefix(frame.Function, "main.") {
+		return true
+	}
+	mainModuleOnce.Do(func() {
+		if info, ok := debug.ReadBuildInfo(); ok {
+			mainModule = info.Main.Path
+		}
+	})
+
+	return mainModule != "" &&
+		(strings.HasPrefix(frame.Function, mainModule+".") ||
+			strings.HasPrefix(frame.Function, mainModule+"/"))
+}
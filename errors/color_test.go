@@ -0,0 +1,70 @@
+package errors_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/StevenACoffman/simplerr/errors"
+	"github.com/StevenACoffman/simplerr/errors/errorstest"
+)
+
+func TestPretty(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("TERM", "xterm-256color")
+
+	err := errors.WrapWithFields(errors.Wrap(errors.New("boom"), "context"), errors.Fields{"user": 42})
+	pretty := errors.Pretty(err)
+	for _, expected := range []string{
+		"\x1b[1m" + err.Error() + "\x1b[0m",
+		"\x1b[36mgithub.com/StevenACoffman/simplerr/errors_test.TestPretty\x1b[0m",
+		"\x1b[2mtesting.tRunner\x1b[0m",
+		"-- Fields: \x1b[35muser\x1b[0m=\x1b[32m42\x1b[0m",
+		"\x1b[2;3m[...repeated from below...]\x1b[0m",
+	} {
+		if !strings.Contains(pretty, expected) {
+			t.Errorf("expected %q in:\n%s", expected, pretty)
+		}
+	}
+
+	plain := errors.FormatWith(err, errors.VerboseFormatter{})
+	if strings.Contains(plain, "\x1b[") {
+		t.Fatalf("unexpected escape codes without Color:\n%s", plain)
+	}
+
+	var buf bytes.Buffer
+	errors.FprintPretty(&buf, err)
+	if buf.String() != plain+"\n" {
+		t.Fatalf("expected no colors when not writing to a terminal, got:\n%s", buf.String())
+	}
+	if errors.ColorAllowed(&buf) {
+		t.Fatal("expected no colors for a buffer")
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	if pretty := errors.Pretty(err); pretty != plain {
+		t.Fatalf("expected NO_COLOR to disable colors, got:\n%s", pretty)
+	}
+}
+
+func TestPrettyFormatter(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	f, ok := errors.LookupFormatter(errors.FormatterPretty)
+	if !ok {
+		t.Fatal("expected the pretty formatter to be registered")
+	}
+	err := errors.WrapWithFields(errors.New("boom"), errors.Fields{"user": 42})
+	if pretty, plain := errors.FormatWith(err, f), errors.FormatWith(err, errors.VerboseFormatter{}); pretty != plain {
+		t.Fatalf("expected NO_COLOR to disable colors, got:\n%s", pretty)
+	}
+
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("TERM", "xterm-256color")
+	pretty := errors.FormatWith(err, f)
+	if !strings.Contains(pretty, "\x1b[") {
+		t.Fatalf("expected colors, got:\n%s", pretty)
+	}
+	if plain := errorstest.Normalize(pretty); plain != errorstest.Normalize(errors.FormatWith(err, errors.VerboseFormatter{})) {
+		t.Fatalf("expected the same sections with and without colors, got:\n%s", plain)
+	}
+}
@@ -0,0 +1,218 @@
+// Package errhtml renders error chains as HTML documents, for debug pages
+// of development servers.
+//
+// Each layer of the chain gets a collapsible section, with its fields as
+// a table and its stack frames optionally linked to a code host.
+package errhtml
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/StevenACoffman/simplerr/errors"
+)
+
+// FrameLink is the data the link template of a Renderer is executed with.
+type FrameLink struct {
+	Function string
+	File     string
+	Line     int
+	// Path is File relative to the root of the main module, when the
+	// binary was built with -trimpath; otherwise it is the same as File.
+	Path string
+	// Commit is the VCS revision the binary was built from, if known.
+	Commit string
+}
+
+// Renderer renders the Report of an error as an HTML document.
+// It implements errors.Formatter, so it can also be registered with
+// errors.RegisterFormatter.
+type Renderer struct {
+	link   *texttemplate.Template
+	module string
+	commit string
+}
+
+// compiler enforced interface conformance checks
+var _ errors.Formatter = (*Renderer)(nil)
+
+// New returns a Renderer. If linkTemplate is not empty, it is parsed as a
+// text/template that is executed with a FrameLink to build the link of
+// each stack frame, for example:
+//
+//	https://github.com/org/repo/blob/{{.Commit}}/{{.Path}}#L{{.Line}}
+func New(linkTemplate string) (*Renderer, error) {
+	r := &Renderer{}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		r.module = info.Main.Path
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" {
+				r.commit = setting.Value
+			}
+		}
+	}
+	if linkTemplate != "" {
+		link, err := texttemplate.New("link").Parse(linkTemplate)
+		if err != nil {
+			return nil, errors.Wrap(err, "errhtml: parsing link template")
+		}
+		r.link = link
+	}
+
+	return r, nil
+}
+
+// Render implements errors.Formatter.
+func (r *Renderer) Render(w io.Writer, report *errors.Report) {
+	tmpl := template.Must(page.Clone())
+	tmpl.Funcs(template.FuncMap{"link": r.frameLink})
+	if err := tmpl.Execute(w, report); err != nil {
+		_, _ = fmt.Fprintf(w, "<pre>%s</pre>", template.HTMLEscapeString(err.Error()))
+	}
+}
+
+// RenderError renders err as an HTML document.
+func (r *Renderer) RenderError(w io.Writer, err error) {
+	r.Render(w, errors.NewReport(err))
+}
+
+// frameLink returns the link of frame, or "" if there is no link template.
+func (r *Renderer) frameLink(frame runtime.Frame) string {
+	if r.link == nil {
+		return ""
+	}
+	data := FrameLink{
+		Function: frame.Function,
+		File:     frame.File,
+		Line:     frame.Line,
+		Path:     frame.File,
+		Commit:   r.commit,
+	}
+	if r.module != "" {
+		data.Path = strings.TrimPrefix(frame.File, r.module+"/")
+	}
+	var buf bytes.Buffer
+	if err := r.link.Execute(&buf, data); err != nil {
+		return ""
+	}
+
+	return buf.String()
+}
+
+// Handler serves requests with Handle, and responds with a debug page
+// when it returns an error or panics, if Dev is set. Otherwise, only a
+// generic 500 Internal Server Error is sent.
+type Handler struct {
+	Handle func(w http.ResponseWriter, r *http.Request) error
+	// Dev enables the debug page. It must never be set in production, as
+	// the page discloses stack traces and error details.
+	Dev bool
+	// Renderer renders the debug page. If nil, a Renderer without links
+	// is used.
+	Renderer *Renderer
+}
+
+// ServeHTTP implements http.Handler.
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var err error
+	func() {
+		defer func() {
+			if p := recover(); p != nil {
+				if p == http.ErrAbortHandler {
+					panic(p)
+				}
+				err = errors.WithStackDepth(fmt.Errorf("panic: %v", p), 2)
+			}
+		}()
+		err = h.Handle(w, r)
+	}()
+	if err == nil {
+		return
+	}
+
+	if !h.Dev {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	renderer := h.Renderer
+	if renderer == nil {
+		renderer = &Renderer{}
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusInternalServerError)
+	renderer.RenderError(w, err)
+}
+
+var page = template.Must(template.New("page").Funcs(template.FuncMap{
+	"link": func(runtime.Frame) string { return "" },
+	"inc":  func(i int) int { return i + 1 },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Message}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+h1 { font-size: 1.4em; white-space: pre-wrap; }
+summary { cursor: pointer; padding: .3em 0; }
+summary code { color: #8a5a00; }
+details { border-left: 3px solid #ccc; padding-left: 1em; margin: .5em 0; }
+table { border-collapse: collapse; margin: .5em 0; }
+th, td { border: 1px solid #ddd; padding: .2em .6em; text-align: left; }
+ol.stack { font-family: monospace; }
+ol.stack .file { color: #666; }
+.elided { color: #999; font-style: italic; list-style: none; }
+</style>
+</head>
+<body>
+{{template "report" .}}
+</body>
+</html>
+{{define "report"}}<h1>{{.Message}}</h1>
+{{- if .Hints}}
+<h2>Hints</h2>
+<ul>{{range .Hints}}<li>{{.}}</li>{{end}}</ul>
+{{- end}}
+{{- if .Details}}
+<h2>Details</h2>
+<ul>{{range .Details}}<li>{{.}}</li>{{end}}</ul>
+{{- end}}
+{{- range $i, $layer := .Layers}}
+<details{{if eq $i 0}} open{{end}}>
+<summary>({{inc $i}}) <code>{{$layer.Type}}</code> {{$layer.Message}}</summary>
+{{- if $layer.Fields}}
+<table>{{range $k, $v := $layer.Fields}}<tr><th>{{$k}}</th><td>{{$v}}</td></tr>{{end}}</table>
+{{- end}}
+{{- if or $layer.Frames $layer.ElidedFrames}}
+<ol class="stack">
+{{- range $layer.Frames}}
+<li>{{with link .}}<a href="{{.}}">{{end}}{{.Function}}{{with link .}}</a>{{end}}<br><span class="file">{{.File}}:{{.Line}}</span></li>
+{{- end}}
+{{- if $layer.ElidedFrames}}
+<li class="elided">[...repeated from below...]</li>
+{{- end}}
+</ol>
+{{- end}}
+{{- if $layer.SpawnedFrom}}
+<p>Spawned from:</p>
+<ol class="stack">
+{{- range $layer.SpawnedFrom}}
+<li>{{with link .}}<a href="{{.}}">{{end}}{{.Function}}{{with link .}}</a>{{end}}<br><span class="file">{{.File}}:{{.Line}}</span></li>
+{{- end}}
+</ol>
+{{- end}}
+{{- with $layer.Masked}}
+<p>Cause hidden behind barrier:</p>
+{{template "report" .}}
+{{- end}}
+</details>
+{{- end}}
+{{end}}`))
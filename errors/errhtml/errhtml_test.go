@@ -0,0 +1,101 @@
+package errhtml_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/StevenACoffman/simplerr/errors"
+	"github.com/StevenACoffman/simplerr/errors/errhtml"
+)
+
+func TestRender(t *testing.T) {
+	r, err := errhtml.New("https://example.com/blob/{{.Commit}}/{{.Path}}#L{{.Line}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chain := errors.WithHint(
+		errors.WrapWithFields(errors.New("<script>alert(1)</script>"), errors.Fields{"user": "a&b"}),
+		"check the input",
+	)
+	var buf bytes.Buffer
+	r.RenderError(&buf, chain)
+	page := buf.String()
+
+	for _, expected := range []string{
+		"Cause: &lt;script&gt;alert(1)&lt;/script&gt;</title>",
+		"<li>check the input</li>",
+		"<details open>\n<summary>(1) <code>*errors.withHint</code>",
+		"<tr><th>user</th><td>a&amp;b</td></tr>",
+		`<a href="https://example.com/blob/`,
+		"errhtml_test.TestRender</a>",
+		"[...repeated from below...]",
+	} {
+		if !strings.Contains(page, expected) {
+			t.Errorf("expected %q in:\n%s", expected, page)
+		}
+	}
+	if strings.Contains(page, "<script>") {
+		t.Fatalf("message was not escaped:\n%s", page)
+	}
+
+	if _, err := errhtml.New("{{"); err == nil {
+		t.Fatal("expected an invalid link template to be rejected")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	failing := func(w http.ResponseWriter, r *http.Request) error {
+		return errors.New("database is down")
+	}
+	panicking := func(w http.ResponseWriter, r *http.Request) error {
+		panic("nil map")
+	}
+
+	tests := []struct {
+		name     string
+		handler  errhtml.Handler
+		expected string
+	}{
+		{
+			name:     "production",
+			handler:  errhtml.Handler{Handle: failing},
+			expected: "Internal Server Error\n",
+		},
+		{
+			name:     "dev",
+			handler:  errhtml.Handler{Handle: failing, Dev: true},
+			expected: "<h1>database is down</h1>",
+		},
+		{
+			name:     "panic",
+			handler:  errhtml.Handler{Handle: panicking, Dev: true},
+			expected: "<h1>panic: nil map</h1>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			tt.handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+			if rec.Code != http.StatusInternalServerError {
+				t.Fatalf("expected status 500, got %d", rec.Code)
+			}
+			if !strings.Contains(rec.Body.String(), tt.expected) {
+				t.Fatalf("expected %q in:\n%s", tt.expected, rec.Body.String())
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	ok := errhtml.Handler{Handle: func(w http.ResponseWriter, r *http.Request) error {
+		_, err := w.Write([]byte("ok"))
+		return err
+	}, Dev: true}
+	ok.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+	if rec.Code != http.StatusOK || rec.Body.String() != "ok" {
+		t.Fatalf("unexpected response %d %q", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,142 @@
+// Package errorstest provides test assertions on error chains, so that
+// tests can check what an error is made of rather than its message.
+//
+// Failed assertions report the verbose (%+v) rendering of the error, and
+// let the test go on, like t.Errorf. They return whether they passed.
+package errorstest
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/StevenACoffman/simplerr/errors"
+)
+
+// AssertIs checks that errors.Is(err, target).
+func AssertIs(t testing.TB, err, target error) bool {
+	t.Helper()
+	if errors.Is(err, target) {
+		return true
+	}
+	t.Errorf("expected the chain to match %v, in:\n%+v", target, err)
+
+	return false
+}
+
+// AssertAs checks that an error of type T is in the chain of err, and
+// returns it.
+func AssertAs[T error](t testing.TB, err error) (T, bool) {
+	t.Helper()
+	target, ok := errors.AsType[T](err)
+	if !ok {
+		t.Errorf("expected a %s in the chain, in:\n%+v", typeName[T](), err)
+	}
+
+	return target, ok
+}
+
+// AssertFields checks that the fields of err, as returned by
+// errors.GetFields, include expected. Other fields are ignored.
+func AssertFields(t testing.TB, err error, expected errors.Fields) bool {
+	t.Helper()
+	actual := errors.GetFields(err)
+	ok := true
+	for k, v := range expected {
+		got, found := actual[k]
+		switch {
+		case !found:
+			t.Errorf("expected field %s=%v, but it is missing, in:\n%+v", k, v, err)
+			ok = false
+		case !reflect.DeepEqual(got, v):
+			t.Errorf("expected field %s=%v, got %v, in:\n%+v", k, v, got, err)
+			ok = false
+		}
+	}
+
+	return ok
+}
+
+// AssertStackContains checks that a stack trace of err, or the spawn site
+// of its goroutine, has a frame of function, given either in full, as in
+// "github.com/org/repo/pkg.Func", or as "pkg.Func".
+func AssertStackContains(t testing.TB, err error, function string) bool {
+	t.Helper()
+	for _, layer := range errors.NewReport(err).Layers {
+		for _, frames := range [][]runtime.Frame{layer.Frames, layer.SpawnedFrom} {
+			for _, frame := range frames {
+				if frame.Function == function || strings.HasSuffix(frame.Function, "/"+function) {
+					return true
+				}
+			}
+		}
+	}
+	t.Errorf("expected %s in a stack trace, in:\n%+v", function, err)
+
+	return false
+}
+
+// AssertChainTypes checks the types of the errors in the chain of err,
+// outermost first, as printed by %T, such as "*errors.withStack".
+func AssertChainTypes(t testing.TB, err error, types ...string) bool {
+	t.Helper()
+	var actual []string
+	for _, layer := range errors.NewReport(err).Layers {
+		actual = append(actual, layer.Type)
+	}
+	if reflect.DeepEqual(actual, types) {
+		return true
+	}
+	t.Errorf("expected the chain types %s, got %s, in:\n%+v",
+		strings.Join(types, " "), strings.Join(actual, " "), err)
+
+	return false
+}
+
+var (
+	ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*m")
+	fileLine   = regexp.MustCompile(`(?m)^((?:  \| )?\t)(?:.*/)?([^/\s]+\.(?:go|s)):\d+(?: \+0x[0-9a-f]+)?$`)
+	function   = regexp.MustCompile(`(?m)^((?:  -- (?:Stack trace|Spawned from):)|  \| )([^\s\[]\S*)$`)
+	goroutine  = regexp.MustCompile(`(?m)^goroutine \d+ `)
+	pointer    = regexp.MustCompile(`0x[0-9a-f]+`)
+)
+
+// Normalize makes a rendering of an error stable across machines, builds
+// and edits, for comparison with golden files: it strips ANSI colors, the
+// directories of files, line numbers, goroutine IDs and hexadecimal
+// values such as program counters. For example, the frame line
+// "  | \t/home/me/src/app/db.go:42" becomes "  | \tdb.go:N".
+//
+// Functions of stack traces keep only the last element of their package
+// path, so "github.com/org/app/db.Open" becomes "db.Open". Packages built
+// from a list of files, whose path is "command-line-arguments", are shown
+// as "main".
+func Normalize(s string) string {
+	s = ansiEscape.ReplaceAllString(s, "")
+	s = fileLine.ReplaceAllString(s, "${1}${2}:N")
+	s = function.ReplaceAllStringFunc(s, func(line string) string {
+		m := function.FindStringSubmatch(line)
+		return m[1] + shortFunction(m[2])
+	})
+	s = goroutine.ReplaceAllString(s, "goroutine N ")
+
+	return pointer.ReplaceAllString(s, "0x?")
+}
+
+// shortFunction strips the package path of a function name but its last
+// element.
+func shortFunction(name string) string {
+	name = strings.Replace(name, "command-line-arguments", "main", 1)
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+
+	return name
+}
+
+func typeName[T any]() string {
+	return fmt.Sprintf("%v", reflect.TypeOf((*T)(nil)).Elem())
+}
@@ -0,0 +1,103 @@
+package errorstest_test
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/StevenACoffman/simplerr/errors"
+	"github.com/StevenACoffman/simplerr/errors/errorstest"
+)
+
+// update is defined as in many test packages, which must not clash with
+// the flag of errorstest.
+var _ = flag.Bool("update", false, "update the golden files")
+
+// recorder records the failures of assertions instead of failing the test.
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func loadConfig() error {
+	return errors.WrapWithFields(errors.Wrap(&os.PathError{Op: "open", Path: "/etc/app", Err: io.EOF}, "loading config"),
+		errors.Fields{"attempt": 2})
+}
+
+func TestAssertions(t *testing.T) {
+	err := loadConfig()
+	tests := []struct {
+		name   string
+		assert func(t testing.TB) bool
+		passes bool
+	}{
+		{name: "Is", assert: func(t testing.TB) bool { return errorstest.AssertIs(t, err, io.EOF) }, passes: true},
+		{name: "not Is", assert: func(t testing.TB) bool { return errorstest.AssertIs(t, err, io.ErrUnexpectedEOF) }},
+		{name: "As", assert: func(t testing.TB) bool {
+			pathErr, ok := errorstest.AssertAs[*os.PathError](t, err)
+			return ok && pathErr.Path == "/etc/app"
+		}, passes: true},
+		{name: "not As", assert: func(t testing.TB) bool {
+			_, ok := errorstest.AssertAs[*os.LinkError](t, err)
+			return ok
+		}},
+		{name: "Fields", assert: func(t testing.TB) bool {
+			return errorstest.AssertFields(t, err, errors.Fields{"attempt": 2})
+		}, passes: true},
+		{name: "wrong Fields", assert: func(t testing.TB) bool {
+			return errorstest.AssertFields(t, err, errors.Fields{"attempt": 3, "user": "bob"})
+		}},
+		{name: "StackContains", assert: func(t testing.TB) bool {
+			return errorstest.AssertStackContains(t, err, "errorstest_test.loadConfig")
+		}, passes: true},
+		{name: "StackContains full name", assert: func(t testing.TB) bool {
+			return errorstest.AssertStackContains(t, err, "github.com/StevenACoffman/simplerr/errors/errorstest_test.loadConfig")
+		}, passes: true},
+		{name: "not StackContains", assert: func(t testing.TB) bool {
+			return errorstest.AssertStackContains(t, err, "errorstest_test.saveConfig")
+		}},
+		{name: "ChainTypes", assert: func(t testing.TB) bool {
+			return errorstest.AssertChainTypes(t, err, "*errors.withFields", "*errors.wrapper", "*fs.PathError", "*errors.errorString")
+		}, passes: true},
+		{name: "wrong ChainTypes", assert: func(t testing.TB) bool {
+			return errorstest.AssertChainTypes(t, err, "*errors.withFields")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &recorder{TB: t}
+			if passed := tt.assert(r); passed != tt.passes {
+				t.Fatalf("expected the assertion to return %t", tt.passes)
+			}
+			if tt.passes != (len(r.failures) == 0) {
+				t.Fatalf("unexpected failures %q", r.failures)
+			}
+			for _, failure := range r.failures {
+				if !strings.Contains(failure, "\n(1) attempt=2") && !strings.Contains(failure, "-- Stack trace:") {
+					t.Errorf("expected the verbose rendering in the failure:\n%s", failure)
+				}
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	text := "(1) boom\n  -- Stack trace:\x1b[36mgithub.com/org/app.run\x1b[0m\n  | \t/home/me/src/app/db.go:42\n" +
+		"  | testing.tRunner\n  | \t/usr/local/go/src/testing/testing.go:1576\n" +
+		"goroutine 17 [running]:\nmain.main()\n\t/tmp/main.go:5 +0x1d\nError types: (1) *errors.withStack &errors.Stack{0x4a2b1c}"
+	expected := "(1) boom\n  -- Stack trace:app.run\n  | \tdb.go:N\n" +
+		"  | testing.tRunner\n  | \ttesting.go:N\n" +
+		"goroutine N [running]:\nmain.main()\n\tmain.go:N\nError types: (1) *errors.withStack &errors.Stack{0x?}"
+	if actual := errorstest.Normalize(text); actual != expected {
+		t.Fatalf("expected:\n%s\nbut got:\n%s", expected, actual)
+	}
+}
@@ -0,0 +1,59 @@
+package errorstest
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// update is namespaced, as the test packages that use errorstest often
+// define an -update flag of their own.
+var update = flag.Bool("errorstest.update", false, "update the golden files of errorstest.AssertGolden")
+
+// Updating reports whether the test binary was run with
+// -errorstest.update, to rewrite golden files rather than compare with
+// them.
+func Updating() bool {
+	return *update
+}
+
+// GoldenPath returns the path of the golden file called name:
+// testdata/<name>.golden.
+func GoldenPath(name string) string {
+	return filepath.Join("testdata", name+".golden")
+}
+
+// AssertGolden checks that actual, once normalized with Normalize, is the
+// content of the golden file called name. When the test binary is run
+// with -errorstest.update, the golden file is written instead:
+//
+//	go test ./... -errorstest.update
+func AssertGolden(t testing.TB, name, actual string) bool {
+	t.Helper()
+	actual = Normalize(actual)
+	path := GoldenPath(name)
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("updating golden file: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(actual+"\n"), 0o644); err != nil {
+			t.Fatalf("updating golden file: %v", err)
+		}
+		return true
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Errorf("reading golden file (run the test with -errorstest.update to create it): %v", err)
+		return false
+	}
+	if strings.TrimSuffix(string(expected), "\n") != actual {
+		t.Errorf("output differs from %s (run the test with -errorstest.update to accept it)\nexpected:\n%s\nbut got:\n%s",
+			path, expected, actual)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,141 @@
+package errparse
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"strings"
+
+	"github.com/StevenACoffman/simplerr/errors"
+)
+
+// Dump is an error dump found by Extract.
+type Dump struct {
+	// Line is the 1-based number of the line the dump starts on.
+	Line int
+	// Text is the text of the dump, without the log prefix of its first
+	// line, if any.
+	Text string
+	// Report is the parsed dump.
+	Report *errors.Report
+}
+
+// maxDumpLines bounds how far Extract looks for the "Error types:" line
+// that ends the layers of a verbose dump.
+const maxDumpLines = 10000
+
+// Extract finds the error dumps in r, such as a log file. Verbose
+// renderings may start anywhere on a line, after a log prefix such as a
+// timestamp; goroutine dumps must start at the beginning of a line.
+func Extract(r io.Reader) ([]Dump, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, strings.TrimRight(ansiEscape.ReplaceAllString(scanner.Text(), ""), "\r"))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "errparse: reading input")
+	}
+
+	var dumps []Dump
+	for i := 0; i < len(lines); i++ {
+		var end int
+		var text string
+		if start := strings.Index(lines[i], "(1)"); start >= 0 {
+			end = verboseEnd(lines, i)
+			if end < 0 {
+				continue
+			}
+			text = strings.Join(append([]string{lines[i][start:]}, lines[i+1:end]...), "\n")
+		} else if strings.HasPrefix(lines[i], "panic: ") ||
+			strings.HasPrefix(lines[i], "fatal error: ") ||
+			goroutineHeader.MatchString(lines[i]) {
+			end = goroutinesEnd(lines, i)
+			text = strings.Join(lines[i:end], "\n")
+		} else {
+			continue
+		}
+
+		report, err := Parse(text)
+		if err != nil {
+			continue
+		}
+		dumps = append(dumps, Dump{Line: i + 1, Text: text, Report: report})
+		i = end - 1
+	}
+
+	return dumps, nil
+}
+
+// verboseEnd returns the index of the line after the verbose dump starting
+// at lines[start], or -1 if there is none.
+func verboseEnd(lines []string, start int) int {
+	end := -1
+	for i := start; i < len(lines) && i-start < maxDumpLines; i++ {
+		if strings.HasPrefix(lines[i], typesHeader) {
+			end = i + 1
+			break
+		}
+		if i > start && strings.Contains(lines[i], "(1)") && !strings.HasPrefix(lines[i], detailPrefix) {
+			return -1
+		}
+	}
+	if end < 0 {
+		return -1
+	}
+	// The return trace, hints, details and, in older versions, the stack
+	// of the outermost error may follow the error types.
+	for end < len(lines) {
+		switch {
+		case lines[end] == returnHeader, lines[end] == hintsHeader, lines[end] == detailsHeader,
+			strings.HasPrefix(lines[end], stackHeader),
+			strings.HasPrefix(lines[end], detailPrefix):
+			end++
+		default:
+			return end
+		}
+	}
+
+	return end
+}
+
+// goroutinesEnd returns the index of the line after the goroutine dump
+// starting at lines[start].
+func goroutinesEnd(lines []string, start int) int {
+	end := start + 1
+	for i := start + 1; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case line == "":
+			continue
+		case goroutineHeader.MatchString(line),
+			strings.HasPrefix(line, "\t"),
+			strings.HasPrefix(line, "panic: "),
+			strings.HasPrefix(line, "...additional frames elided..."),
+			i+1 < len(lines) && strings.HasPrefix(lines[i+1], "\t"):
+			end = i + 1
+		default:
+			return end
+		}
+	}
+
+	return end
+}
+
+// Fingerprint returns a stable identifier of the shape of report: the
+// types of its layers and the functions of their stack frames. Messages,
+// file paths and line numbers are left out, as they vary between
+// occurrences of the same error and between builds.
+func Fingerprint(report *errors.Report) string {
+	h := sha256.New()
+	for _, layer := range report.Layers {
+		_, _ = io.WriteString(h, layer.Type+"\n")
+		for _, frame := range layer.Frames {
+			_, _ = io.WriteString(h, frame.Function+"\n")
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
@@ -0,0 +1,305 @@
+// Package errparse turns the text of error dumps back into structured
+// reports, so that errors that only survive in logs can be re-rendered,
+// fingerprinted and grouped.
+//
+// Two kinds of dumps are understood: the verbose (%+v) rendering of this
+// module's errors, as produced by errors.VerboseFormatter, and goroutine
+// dumps, as printed by runtime/debug.Stack or by an unrecovered panic.
+// Both are parsed into an *errors.Report, with no live error values.
+package errparse
+
+import (
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/StevenACoffman/simplerr/errors"
+)
+
+// ErrNotADump is returned when the text is not a dump this package
+// understands.
+var ErrNotADump = errors.Sentinel("errparse: not an error dump")
+
+const (
+	detailPrefix   = "  | "
+	stackHeader    = "  -- Stack trace:"
+	fieldsHeader   = "  -- Fields:"
+	barrierHeader  = "  -- cause hidden behind barrier:"
+	spawnedHeader  = "  -- Spawned from:"
+	detailHeader   = "  -- Detail:"
+	elidedMarker   = "[...repeated from below...]"
+	typesHeader    = "Error types:"
+	returnHeader   = "Return trace:"
+	hintsHeader    = "Hints:"
+	detailsHeader  = "Details:"
+	goroutineType  = "goroutine"
+	createdByLabel = "created by "
+)
+
+var (
+	ansiEscape      = regexp.MustCompile("\x1b\\[[0-9;]*m")
+	layerHeader     = regexp.MustCompile(`^(?:\(1\)|Wraps: \((\d+)\))(.*)$`)
+	typeRef         = regexp.MustCompile(` \((\d+)\) `)
+	goroutineHeader = regexp.MustCompile(`^goroutine \d+ \[.*\]:$`)
+	fileLine        = regexp.MustCompile(`^\t(.*):(\d+)(?: \+0x[0-9a-f]+)?$`)
+)
+
+// Parse parses text as either a verbose error rendering or a goroutine
+// dump, whichever it looks like.
+func Parse(text string) (*errors.Report, error) {
+	text = strings.TrimSpace(ansiEscape.ReplaceAllString(text, ""))
+	if strings.HasPrefix(text, "(1)") {
+		return ParseVerbose(text)
+	}
+
+	return ParseGoroutines(text)
+}
+
+// ParseVerbose parses the verbose (%+v) rendering of an error, as produced
+// by errors.VerboseFormatter, with or without colors.
+func ParseVerbose(text string) (*errors.Report, error) {
+	lines := strings.Split(strings.TrimRight(ansiEscape.ReplaceAllString(text, ""), "\n"), "\n")
+	if len(lines) == 0 || !strings.HasPrefix(lines[0], "(1)") {
+		return nil, ErrNotADump
+	}
+	p := &verboseParser{lines: lines}
+	report := p.parse()
+	if report == nil {
+		return nil, ErrNotADump
+	}
+
+	return report, nil
+}
+
+type verboseParser struct {
+	lines []string
+	pos   int
+}
+
+func (p *verboseParser) parse() *errors.Report {
+	report := &errors.Report{}
+	sawTypes := false
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		switch {
+		case layerHeader.MatchString(line):
+			m := layerHeader.FindStringSubmatch(line)
+			p.pos++
+			report.Layers = append(report.Layers, p.parseLayer(m[2]))
+		case strings.HasPrefix(line, typesHeader):
+			sawTypes = true
+			p.pos++
+			parseTypes(report, strings.TrimPrefix(line, typesHeader))
+		case line == returnHeader:
+			p.pos++
+			report.ReturnTrace, _ = p.parseFrames("")
+		case line == hintsHeader:
+			p.pos++
+			report.Hints = p.parseDetailLines()
+		case line == detailsHeader:
+			p.pos++
+			report.Details = p.parseDetailLines()
+		case strings.HasPrefix(line, stackHeader) && len(report.Layers) > 0:
+			// Older versions printed the stack of the outermost withStack
+			// after the error types.
+			p.pos++
+			frames, elided := p.parseFrames(strings.TrimPrefix(line, stackHeader))
+			if top := &report.Layers[0]; len(top.Frames) == 0 {
+				top.Frames, top.ElidedFrames = frames, elided
+			}
+		default:
+			// Anything else ends the dump.
+			p.lines = p.lines[:p.pos]
+		}
+	}
+	if len(report.Layers) == 0 || !sawTypes {
+		return nil
+	}
+	report.Message = report.Layers[0].Message
+
+	return report
+}
+
+// parseLayer parses the message and the sections of a layer, given what
+// follows its "(N)" header.
+func (p *verboseParser) parseLayer(rest string) errors.Layer {
+	var layer errors.Layer
+	msg := []string{strings.TrimPrefix(rest, " ")}
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		switch {
+		case strings.HasPrefix(line, stackHeader):
+			p.pos++
+			layer.Frames, layer.ElidedFrames = p.parseFrames(strings.TrimPrefix(line, stackHeader))
+			continue
+		case strings.HasPrefix(line, spawnedHeader):
+			p.pos++
+			layer.SpawnedFrom, _ = p.parseFrames(strings.TrimPrefix(line, spawnedHeader))
+			continue
+		case line == detailHeader:
+			p.pos++
+			layer.Detail = p.parseDetailLines()
+			continue
+		case strings.HasPrefix(line, fieldsHeader):
+			p.pos++
+			continue
+		case line == barrierHeader:
+			p.pos++
+			masked := &verboseParser{lines: p.parseDetailLines()}
+			layer.Masked = masked.parse()
+			continue
+		}
+		if layerHeader.MatchString(line) || strings.HasPrefix(line, typesHeader) {
+			break
+		}
+		if layer.Frames != nil || layer.ElidedFrames || layer.Masked != nil || layer.SpawnedFrom != nil ||
+			layer.Detail != nil {
+			// Sections always come after the whole message.
+			break
+		}
+		msg = append(msg, line)
+		p.pos++
+	}
+	layer.Message = strings.Join(msg, "\n")
+
+	return layer
+}
+
+// parseFrames parses the frames of a stack trace section, given the
+// function name that follows its header.
+func (p *verboseParser) parseFrames(first string) (frames []runtime.Frame, elided bool) {
+	lines := append([]string{first}, p.parseDetailLines()...)
+	for i := 0; i < len(lines); i++ {
+		if lines[i] == elidedMarker {
+			elided = true
+			continue
+		}
+		if lines[i] == "" {
+			continue
+		}
+		frame := runtime.Frame{Function: lines[i]}
+		if i+1 < len(lines) {
+			if m := fileLine.FindStringSubmatch(lines[i+1]); m != nil {
+				frame.File = m[1]
+				frame.Line, _ = strconv.Atoi(m[2])
+				i++
+			}
+		}
+		frames = append(frames, frame)
+	}
+
+	return frames, elided
+}
+
+// parseDetailLines consumes the lines prefixed by "  | " and returns them
+// without their prefix.
+func (p *verboseParser) parseDetailLines() []string {
+	var lines []string
+	for p.pos < len(p.lines) && strings.HasPrefix(p.lines[p.pos], detailPrefix) {
+		lines = append(lines, strings.TrimPrefix(p.lines[p.pos], detailPrefix))
+		p.pos++
+	}
+
+	return lines
+}
+
+// parseTypes assigns the types listed after "Error types:" to the layers.
+func parseTypes(report *errors.Report, types string) {
+	idx := typeRef.FindAllStringSubmatchIndex(types, -1)
+	for i, m := range idx {
+		n, err := strconv.Atoi(types[m[2]:m[3]])
+		if err != nil || n < 1 || n > len(report.Layers) {
+			continue
+		}
+		end := len(types)
+		if i+1 < len(idx) {
+			end = idx[i+1][0]
+		}
+		report.Layers[n-1].Type = types[m[1]:end]
+	}
+}
+
+// ParseGoroutines parses a goroutine dump, as printed by
+// runtime/debug.Stack or by an unrecovered panic. The panic message, if
+// any, becomes the message of the report, and each goroutine becomes a
+// layer of type "goroutine" whose message is its header.
+func ParseGoroutines(text string) (*errors.Report, error) {
+	lines := strings.Split(strings.TrimSpace(ansiEscape.ReplaceAllString(text, "")), "\n")
+	report := &errors.Report{}
+	var preamble []string
+	var layer *errors.Layer
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], "\r")
+		switch {
+		case goroutineHeader.MatchString(line):
+			report.Layers = append(report.Layers, errors.Layer{
+				Message: strings.TrimSuffix(line, ":"),
+				Type:    goroutineType,
+			})
+			layer = &report.Layers[len(report.Layers)-1]
+		case layer == nil:
+			preamble = append(preamble, line)
+		case line == "" || strings.HasPrefix(line, "...additional frames elided..."):
+		case i+1 < len(lines) && fileLine.MatchString(strings.TrimRight(lines[i+1], "\r")):
+			// Lines that are not followed by a file and line, such as
+			// "exit status 2", are not frames.
+			m := fileLine.FindStringSubmatch(strings.TrimRight(lines[i+1], "\r"))
+			frame := runtime.Frame{Function: functionName(line), File: m[1]}
+			frame.Line, _ = strconv.Atoi(m[2])
+			layer.Frames = append(layer.Frames, frame)
+			i++
+		}
+	}
+	if len(report.Layers) == 0 {
+		return nil, ErrNotADump
+	}
+	report.Message = panicMessage(preamble)
+
+	return report, nil
+}
+
+// functionName strips the arguments from a goroutine dump function line,
+// such as "main.(*T).run(0xc000010000, {0x4b2f1d, 0x3})", and the
+// goroutine from a "created by main.main in goroutine 1" line.
+func functionName(line string) string {
+	if strings.HasPrefix(line, createdByLabel) {
+		line = strings.TrimPrefix(line, createdByLabel)
+		if i := strings.Index(line, " in goroutine "); i >= 0 {
+			line = line[:i]
+		}
+		return line
+	}
+	if !strings.HasSuffix(line, ")") {
+		return line
+	}
+	depth := 0
+	for i := len(line) - 1; i >= 0; i-- {
+		switch line[i] {
+		case ')':
+			depth++
+		case '(':
+			depth--
+			if depth == 0 {
+				return line[:i]
+			}
+		}
+	}
+
+	return line
+}
+
+// panicMessage returns the message of the first "panic: " line.
+func panicMessage(preamble []string) string {
+	for _, line := range preamble {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "panic: ") {
+			return strings.TrimSuffix(strings.TrimPrefix(line, "panic: "), " [recovered]")
+		}
+		if strings.HasPrefix(line, "fatal error: ") {
+			return strings.TrimPrefix(line, "fatal error: ")
+		}
+	}
+
+	return strings.TrimSpace(strings.Join(preamble, "\n"))
+}
@@ -0,0 +1,192 @@
+package errparse_test
+
+import (
+	"io"
+	"runtime/debug"
+	"strings"
+	"testing"
+
+	"golang.org/x/xerrors"
+
+	"github.com/StevenACoffman/simplerr/errors"
+	"github.com/StevenACoffman/simplerr/errors/errparse"
+)
+
+func TestParseVerbose(t *testing.T) {
+	err := errors.WithHint(
+		errors.WrapWithFields(errors.Wrap(io.EOF, "reading config"), errors.Fields{"path": "/etc/app"}),
+		"check the file",
+	)
+	for _, color := range []bool{false, true} {
+		text := errors.FormatWith(err, errors.VerboseFormatter{Color: color})
+		actual, parseErr := errparse.Parse(text)
+		if parseErr != nil {
+			t.Fatalf("color=%t: %v\n%s", color, parseErr, text)
+		}
+		expected := errors.NewReport(err)
+
+		if actual.Message != expected.Message {
+			t.Errorf("color=%t: expected message %q, got %q", color, expected.Message, actual.Message)
+		}
+		if len(actual.Layers) != len(expected.Layers) {
+			t.Fatalf("color=%t: expected %d layers, got %d", color, len(expected.Layers), len(actual.Layers))
+		}
+		for i := range expected.Layers {
+			e, a := expected.Layers[i], actual.Layers[i]
+			if a.Type != e.Type || a.Message != e.Message || len(a.Frames) != len(e.Frames) {
+				t.Errorf("color=%t: layer %d: expected %s %q with %d frames, got %s %q with %d frames",
+					color, i+1, e.Type, e.Message, len(e.Frames), a.Type, a.Message, len(a.Frames))
+				continue
+			}
+			for j := range e.Frames {
+				if a.Frames[j].Function != e.Frames[j].Function ||
+					a.Frames[j].File != e.Frames[j].File || a.Frames[j].Line != e.Frames[j].Line {
+					t.Errorf("color=%t: layer %d frame %d: expected %v, got %v", color, i+1, j, e.Frames[j], a.Frames[j])
+				}
+			}
+		}
+		if strings.Join(actual.Hints, ",") != "check the file" {
+			t.Errorf("color=%t: unexpected hints %q", color, actual.Hints)
+		}
+		if errparse.Fingerprint(actual) != errparse.Fingerprint(expected) {
+			t.Errorf("color=%t: expected the fingerprint of the parsed report to match", color)
+		}
+	}
+
+	if _, err := errparse.ParseVerbose("just some text"); err != errparse.ErrNotADump { //errlint:ignore the sentinel itself
+		t.Fatalf("expected ErrNotADump, got %v", err)
+	}
+}
+
+const panicDump = `panic: assignment to entry in nil map
+
+goroutine 6 [running]:
+main.(*store).put(0xc000012345, {0x4b2f1d, 0x3})
+	/tmp/p2.go:12 +0x31
+main.main.func1()
+	/tmp/p2.go:20 +0x67
+created by main.main in goroutine 1
+	/tmp/p2.go:18 +0x25
+exit status 2`
+
+func TestParseGoroutines(t *testing.T) {
+	report, err := errparse.Parse(panicDump)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Message != "assignment to entry in nil map" {
+		t.Fatalf("unexpected message %q", report.Message)
+	}
+	if len(report.Layers) != 1 || report.Layers[0].Message != "goroutine 6 [running]" {
+		t.Fatalf("unexpected layers %+v", report.Layers)
+	}
+	var functions []string
+	for _, frame := range report.Layers[0].Frames {
+		functions = append(functions, frame.Function)
+	}
+	if actual := strings.Join(functions, ","); actual != "main.(*store).put,main.main.func1,main.main" {
+		t.Fatalf("unexpected functions %s", actual)
+	}
+	if frame := report.Layers[0].Frames[0]; frame.File != "/tmp/p2.go" || frame.Line != 12 {
+		t.Fatalf("unexpected frame %+v", frame)
+	}
+
+	report, err = errparse.ParseGoroutines(string(debug.Stack()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(report.Layers[0].Frames[1].Function, "TestParseGoroutines") {
+		t.Fatalf("unexpected frames %+v", report.Layers[0].Frames)
+	}
+}
+
+func TestExtract(t *testing.T) {
+	verbose := errors.FormatWith(errors.New("disk full"), errors.VerboseFormatter{})
+	log := strings.Join([]string{
+		"2026/01/02 15:04:05 starting",
+		"2026/01/02 15:04:06 save failed: " + verbose,
+		"2026/01/02 15:04:07 retrying",
+		panicDump,
+		"2026/01/02 15:04:08 restarted",
+	}, "\n")
+
+	dumps, err := errparse.Extract(strings.NewReader(log))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dumps) != 2 {
+		t.Fatalf("expected 2 dumps, got %d: %+v", len(dumps), dumps)
+	}
+	if dumps[0].Line != 2 || dumps[0].Report.Message != "disk full" || dumps[0].Text != verbose {
+		t.Errorf("unexpected first dump %+v", dumps[0])
+	}
+	if dumps[1].Report.Message != "assignment to entry in nil map" || strings.Contains(dumps[1].Text, "restarted") {
+		t.Errorf("unexpected second dump %+v", dumps[1])
+	}
+}
+
+func TestParseSpawnSite(t *testing.T) {
+	err := <-errors.Go(func() error { return errors.New("boom") })
+	report, parseErr := errparse.Parse(errors.FormatWith(err, errors.VerboseFormatter{}))
+	if parseErr != nil {
+		t.Fatal(parseErr)
+	}
+	expected := errors.NewReport(err).Layers[0].SpawnedFrom
+	actual := report.Layers[0].SpawnedFrom
+	if len(actual) != len(expected) || actual[0].Function != expected[0].Function || actual[0].Line != expected[0].Line {
+		t.Fatalf("expected spawn site %v, got %v", expected, actual)
+	}
+	if len(report.Layers) != 3 || report.Layers[0].Message != "boom" {
+		t.Fatalf("unexpected layers %+v", report.Layers)
+	}
+}
+
+func TestParseReturnTrace(t *testing.T) {
+	err := errors.Trace(errors.Wrap(errors.New("boom"), "loading"))
+	text := errors.FormatWith(errors.WithHint(err, "retry"), errors.VerboseFormatter{})
+	dumps, extractErr := errparse.Extract(strings.NewReader("start\n" + text + "\nend\n"))
+	if extractErr != nil {
+		t.Fatal(extractErr)
+	}
+	if len(dumps) != 1 {
+		t.Fatalf("expected 1 dump, got %d", len(dumps))
+	}
+	report := dumps[0].Report
+	expected := errors.NewReport(err).ReturnTrace
+	actual := report.ReturnTrace
+	if len(actual) != 2 || len(expected) != 2 {
+		t.Fatalf("expected 2 hops, got %v, %v", actual, expected)
+	}
+	for i := range actual {
+		if actual[i].Function != expected[i].Function || actual[i].Line != expected[i].Line {
+			t.Errorf("hop %d: expected %v, got %v", i, expected[i], actual[i])
+		}
+	}
+	if len(report.Hints) != 1 || report.Hints[0] != "retry" {
+		t.Errorf("expected the hint after the return trace, got %v", report.Hints)
+	}
+}
+
+// detailError prints a detail that is not a location, for xerrors.
+type detailError struct{}
+
+func (detailError) Error() string { return "rejected" }
+
+func (detailError) FormatError(p xerrors.Printer) error {
+	p.Print("rejected")
+	if p.Detail() {
+		p.Print("status: 403")
+	}
+	return nil
+}
+
+func TestParseDetail(t *testing.T) {
+	err := errors.WithStack(detailError{})
+	report, parseErr := errparse.Parse(errors.FormatWith(err, errors.VerboseFormatter{}))
+	if parseErr != nil {
+		t.Fatal(parseErr)
+	}
+	if len(report.Layers) != 2 || len(report.Layers[1].Detail) != 1 || report.Layers[1].Detail[0] != "status: 403" {
+		t.Fatalf("unexpected layers %+v", report.Layers)
+	}
+}
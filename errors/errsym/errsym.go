@@ -0,0 +1,137 @@
+// Package errsym resolves stacks encoded with errors.Stack.Encode into
+// frames, offline, using the symbol table of the binary that encoded them.
+//
+// This lets hot paths log only a compact token, such as
+//
+//	stk1:Xn3f.../abc:-1f2a,3c,1b0
+//
+// and defer the cost of symbolization to whoever reads the logs. Only ELF
+// binaries that were not stripped of their .gopclntab section are
+// supported. Frames of inlined functions are reported as the function they
+// were inlined into.
+package errsym
+
+import (
+	"debug/elf"
+	"debug/gosym"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/StevenACoffman/simplerr/errors"
+	"github.com/StevenACoffman/simplerr/errors/internal/buildid"
+)
+
+// anchorFunction is the name of the function the offsets of an encoded
+// stack are relative to.
+const anchorFunction = "github.com/StevenACoffman/simplerr/errors.Callers"
+
+// ErrBuildIDMismatch is returned when a stack was encoded by another
+// binary than the one the Symbolizer was opened with.
+var ErrBuildIDMismatch = errors.Sentinel("errsym: stack was encoded by another build")
+
+// EncodedStackPattern matches the encoded stacks in a text, such as a log.
+var EncodedStackPattern = regexp.MustCompile(`stk1:[A-Za-z0-9_/+=-]*:(?:-?[0-9a-f]+(?:,-?[0-9a-f]+)*)?`)
+
+// Symbolizer resolves encoded stacks against the symbol table of a binary.
+type Symbolizer struct {
+	buildID string
+	table   *gosym.Table
+	anchor  uint64
+}
+
+// Open reads the symbol table of the ELF binary at path.
+func Open(path string) (*Symbolizer, error) {
+	f, err := elf.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "errsym: opening binary")
+	}
+	defer f.Close()
+
+	return newSymbolizer(f)
+}
+
+func newSymbolizer(f *elf.File) (*Symbolizer, error) {
+	text := f.Section(".text")
+	pclntab := f.Section(".gopclntab")
+	if text == nil || pclntab == nil {
+		return nil, errors.New("errsym: binary has no .text or .gopclntab section")
+	}
+	data, err := pclntab.Data()
+	if err != nil {
+		return nil, errors.Wrap(err, "errsym: reading .gopclntab")
+	}
+	table, err := gosym.NewTable(nil, gosym.NewLineTable(data, text.Addr))
+	if err != nil {
+		return nil, errors.Wrap(err, "errsym: parsing .gopclntab")
+	}
+	fn := table.LookupFunc(anchorFunction)
+	if fn == nil {
+		return nil, errors.New("errsym: binary does not use " + anchorFunction)
+	}
+	// A binary without a build ID can still be symbolized, at the risk of
+	// mixing up builds.
+	id, _ := buildid.Read(f)
+
+	return &Symbolizer{buildID: id, table: table, anchor: fn.Entry}, nil
+}
+
+// BuildID returns the Go build ID of the binary.
+func (s *Symbolizer) BuildID() string {
+	return s.buildID
+}
+
+// Symbolize resolves the frames of an encoded stack, outermost call last,
+// like runtime.CallersFrames.
+func (s *Symbolizer) Symbolize(encoded string) ([]runtime.Frame, error) {
+	enc, err := errors.DecodeStack(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if enc.BuildID != "" && s.buildID != "" && enc.BuildID != s.buildID {
+		return nil, ErrBuildIDMismatch
+	}
+	frames := make([]runtime.Frame, 0, len(enc.Offsets))
+	for _, off := range enc.Offsets {
+		// Like runtime.CallersFrames, look up the call instruction rather
+		// than the return address the program counter points to.
+		pc := uint64(int64(s.anchor)+off) - 1
+		file, line, fn := s.table.PCToLine(pc)
+		frame := runtime.Frame{PC: uintptr(pc + 1), File: file, Line: line}
+		if fn == nil {
+			frame.Function = "?"
+		} else {
+			frame.Function = fn.Name
+			frame.Entry = uintptr(fn.Entry)
+		}
+		frames = append(frames, frame)
+	}
+
+	return frames, nil
+}
+
+// Expand replaces the encoded stacks in text with their frames, rendered
+// like StackTrace.String: one function and one tab-indented file:line per
+// frame, without the outermost runtime frame. Stacks that cannot be
+// symbolized are left as is.
+func (s *Symbolizer) Expand(text string) string {
+	return EncodedStackPattern.ReplaceAllStringFunc(text, func(encoded string) string {
+		frames, err := s.Symbolize(encoded)
+		if err != nil {
+			return encoded
+		}
+		if len(frames) > 0 {
+			frames = frames[:len(frames)-1]
+		}
+		var b strings.Builder
+		for i, frame := range frames {
+			if i > 0 {
+				b.WriteByte('\n')
+			}
+			b.WriteString(frame.Function + "\n\t" + frame.File + ":" + strconv.Itoa(frame.Line))
+		}
+
+		return b.String()
+	})
+}
@@ -0,0 +1,83 @@
+package errsym_test
+
+import (
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/StevenACoffman/simplerr/errors"
+	"github.com/StevenACoffman/simplerr/errors/errsym"
+)
+
+func openSelf(t *testing.T) *errsym.Symbolizer {
+	t.Helper()
+	if runtime.GOOS != "linux" {
+		t.Skip("symbolization needs an ELF binary")
+	}
+	path, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := errsym.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+func TestSymbolize(t *testing.T) {
+	s := openSelf(t)
+	err := errors.New("boom")
+	st := errors.GetStack(err)
+	encoded := st.Encode()
+	if !errsym.EncodedStackPattern.MatchString(encoded) || !strings.HasPrefix(encoded, "stk1:"+s.BuildID()+":") {
+		t.Fatalf("unexpected encoding %q", encoded)
+	}
+
+	actual, symErr := s.Symbolize(encoded)
+	if symErr != nil {
+		t.Fatal(symErr)
+	}
+	frames := runtime.CallersFrames(*st)
+	for i := 0; ; i++ {
+		expected, more := frames.Next()
+		if i >= len(actual) {
+			t.Fatalf("expected %d frames, got %d", i+1, len(actual))
+		}
+		if actual[i].Function != expected.Function || actual[i].File != expected.File || actual[i].Line != expected.Line {
+			t.Errorf("frame %d: expected %s %s:%d, got %s %s:%d", i,
+				expected.Function, expected.File, expected.Line,
+				actual[i].Function, actual[i].File, actual[i].Line)
+		}
+		if !more {
+			break
+		}
+	}
+
+	expanded := s.Expand("failed: " + encoded + " (retrying)")
+	if expanded != "failed: "+st.StackTrace().String()+" (retrying)" {
+		t.Fatalf("unexpected expansion:\n%s", expanded)
+	}
+}
+
+func TestDecodeStack(t *testing.T) {
+	enc, err := errors.DecodeStack("stk1:abc/def:-1f,0,2a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if enc.BuildID != "abc/def" || len(enc.Offsets) != 3 || enc.Offsets[0] != -0x1f || enc.Offsets[2] != 0x2a {
+		t.Fatalf("unexpected decoding %+v", enc)
+	}
+	for _, bad := range []string{"abc", "stk1:abc", "stk1:abc:xyz"} {
+		if _, err := errors.DecodeStack(bad); !errors.Is(err, errors.ErrMalformedStack) {
+			t.Errorf("expected %q to be rejected, got %v", bad, err)
+		}
+	}
+
+	s := openSelf(t)
+	if _, err := s.Symbolize("stk1:other:0"); err != errsym.ErrBuildIDMismatch { //errlint:ignore the sentinel itself
+		t.Fatalf("expected ErrBuildIDMismatch, got %v", err)
+	}
+}
@@ -0,0 +1,369 @@
+package errors
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// A Formatter renders the Report of an error chain. The Formatter
+// selected with SetDefaultFormatter is used for the verbose (%+v) output
+// of every error in this package; FormatWith uses a specific one.
+type Formatter interface {
+	Render(w io.Writer, r *Report)
+}
+
+// Names of the built-in formatters.
+const (
+	// FormatterVerbose is the cockroachdb/errors style layout, with
+	// numbered layers and their stack traces. This is the default.
+	FormatterVerbose = "verbose"
+	// FormatterCompact renders one line per layer.
+	FormatterCompact = "compact"
+	// FormatterPkgErrors mirrors the %+v output of github.com/pkg/errors.
+	FormatterPkgErrors = "pkgerrors"
+	// FormatterPretty is the verbose layout with ANSI colors, unless the
+	// environment disallows them, as for Pretty.
+	FormatterPretty = "pretty"
+)
+
+var (
+	formattersMu sync.RWMutex
+	formatters   = map[string]Formatter{
+		FormatterVerbose:   VerboseFormatter{},
+		FormatterCompact:   CompactFormatter{},
+		FormatterPkgErrors: PkgErrorsFormatter{},
+		FormatterPretty:    prettyFormatter{},
+	}
+	defaultFormatter Formatter = VerboseFormatter{}
+)
+
+// RegisterFormatter makes f available under name, replacing any formatter
+// previously registered under that name.
+func RegisterFormatter(name string, f Formatter) {
+	formattersMu.Lock()
+	defer formattersMu.Unlock()
+	formatters[name] = f
+}
+
+// LookupFormatter returns the formatter registered under name.
+func LookupFormatter(name string) (Formatter, bool) {
+	formattersMu.RLock()
+	defer formattersMu.RUnlock()
+	f, ok := formatters[name]
+
+	return f, ok
+}
+
+// SetDefaultFormatter selects the registered formatter used for the
+// verbose (%+v) output of errors.
+func SetDefaultFormatter(name string) error {
+	formattersMu.Lock()
+	defer formattersMu.Unlock()
+	f, ok := formatters[name]
+	if !ok {
+		return fmt.Errorf("errors: no formatter registered as %q", name)
+	}
+	defaultFormatter = f
+
+	return nil
+}
+
+// DefaultFormatter returns the formatter used for the verbose (%+v)
+// output of errors.
+func DefaultFormatter() Formatter {
+	formattersMu.RLock()
+	defer formattersMu.RUnlock()
+
+	return defaultFormatter
+}
+
+// FormatWith renders err with f, regardless of the default formatter.
+func FormatWith(err error, f Formatter) string {
+	var buf bytes.Buffer
+	f.Render(&buf, NewReport(err))
+
+	return buf.String()
+}
+
+// formatError implements fmt.Formatter for the errors of this package,
+// mirroring github.com/pkg/errors:
+//
+//	%s, %v  the message, as returned by Error()
+//	%q      the message, double-quoted
+//	%+v     the verbose rendering of the chain, by the default Formatter
+//	%#v     a Go-syntax representation, as returned by GoString()
+func formatError(st fmt.State, verb rune, err error) {
+	switch verb {
+	case 'v':
+		switch {
+		case st.Flag('+'):
+			DefaultFormatter().Render(st, NewReport(err))
+		case st.Flag('#'):
+			if gs, ok := err.(fmt.GoStringer); ok {
+				_, _ = io.WriteString(st, gs.GoString())
+				return
+			}
+			_, _ = fmt.Fprintf(st, "%T(%q)", err, err.Error())
+		default:
+			_, _ = io.WriteString(st, err.Error())
+		}
+	case 's':
+		_, _ = io.WriteString(st, err.Error())
+	case 'q':
+		_, _ = fmt.Fprintf(st, "%q", err.Error())
+	default:
+		// Same as what fmt does for a bad verb.
+		_, _ = fmt.Fprintf(st, "%%!%c(%T=%s)", verb, err, err.Error())
+	}
+}
+
+// VerboseFormatter renders the cockroachdb/errors style layout:
+//
+//	(1) <message>
+//	  -- Fields: <key>=<value> ...
+//	  -- Stack trace:<frames>
+//	Wraps: (2) <message>
+//	Error types: (1) <type> (2) <type>
+//
+// followed by the hints and details, if any.
+//
+// With Color set, the output is decorated with ANSI escape codes:
+// messages are bold, frames of the main module are highlighted while
+// those of dependencies and the standard library are dimmed, and the
+// keys and values of fields have colors of their own.
+type VerboseFormatter struct {
+	Color bool
+}
+
+// Render implements Formatter.
+func (f VerboseFormatter) Render(w io.Writer, r *Report) {
+	if len(r.Layers) == 0 {
+		return
+	}
+	for i := range r.Layers {
+		// The first entry at the top is special, it is just (1).
+		// All the entries that follow are printed as Wraps: (N).
+		if i == 0 {
+			_, _ = io.WriteString(w, "(1)")
+		} else {
+			_, _ = fmt.Fprintf(w, "\nWraps: (%d)", i+1)
+		}
+		f.renderLayer(w, &r.Layers[i])
+	}
+
+	// At the end, we link all the (N) references to the Go type of the
+	// error.
+	_, _ = io.WriteString(w, "\nError types:")
+	for i := range r.Layers {
+		_, _ = fmt.Fprintf(w, " (%d) ", i+1)
+		f.paint(w, ansiYellow, r.Layers[i].Type)
+	}
+
+	if len(r.ReturnTrace) > 0 {
+		_, _ = io.WriteString(w, "\nReturn trace:")
+		_, _ = w.Write(detailSep)
+		f.renderFrames(w, r.ReturnTrace)
+	}
+
+	// Hints and details are user-facing, so they get their own sections
+	// after the error types rather than being interleaved with the stacks.
+	f.renderSection(w, "Hints:", r.Hints)
+	f.renderSection(w, "Details:", r.Details)
+}
+
+func (f VerboseFormatter) renderLayer(w io.Writer, layer *Layer) {
+	if len(layer.Message) > 0 {
+		if !strings.HasPrefix(layer.Message, "\n") {
+			_, _ = io.WriteString(w, " ")
+		}
+		f.paint(w, ansiBold, layer.Message)
+	}
+	if len(layer.Fields) > 0 {
+		f.renderFields(w, layer.Fields)
+	}
+	if len(layer.Frames) > 0 || layer.ElidedFrames {
+		_, _ = io.WriteString(w, "\n  -- Stack trace:")
+		f.renderFrames(w, layer.Frames)
+	}
+	if layer.ElidedFrames {
+		_, _ = w.Write(detailSep)
+		f.paint(w, ansiDimItalic, "[...repeated from below...]")
+	}
+	if len(layer.SpawnedFrom) > 0 {
+		_, _ = io.WriteString(w, "\n  -- Spawned from:")
+		f.renderFrames(w, layer.SpawnedFrom)
+	}
+	if len(layer.Detail) > 0 {
+		_, _ = io.WriteString(w, "\n  -- Detail:")
+		for _, line := range layer.Detail {
+			_, _ = w.Write(detailSep)
+			_, _ = io.WriteString(w, line)
+		}
+	}
+	if layer.Masked != nil {
+		var buf bytes.Buffer
+		f.Render(&buf, layer.Masked)
+		_, _ = io.WriteString(w, "\n  -- cause hidden behind barrier:")
+		_, _ = w.Write(detailSep)
+		_, _ = io.WriteString(w, strings.ReplaceAll(buf.String(), "\n", string(detailSep)))
+	}
+}
+
+// renderFrames writes the function and the file:line of each frame on
+// their own detail lines, the first function right after the section title.
+func (f VerboseFormatter) renderFrames(w io.Writer, frames []runtime.Frame) {
+	for i, frame := range frames {
+		if i > 0 {
+			_, _ = w.Write(detailSep)
+		}
+		code := ansiDim
+		if isAppFrame(frame) {
+			code = ansiCyan
+		}
+		f.paint(w, code, frame.Function)
+		_, _ = w.Write(detailSep)
+		_, _ = io.WriteString(w, "\t")
+		f.paint(w, code, frame.File+":"+strconv.Itoa(frame.Line))
+	}
+}
+
+// renderFields lists fields as sorted key=value pairs.
+func (f VerboseFormatter) renderFields(w io.Writer, fields Fields) {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	_, _ = io.WriteString(w, "\n  -- Fields:")
+	for _, k := range keys {
+		_, _ = io.WriteString(w, " ")
+		f.paint(w, ansiMagenta, k)
+		_, _ = io.WriteString(w, "=")
+		f.paint(w, ansiGreen, fmt.Sprint(fields[k]))
+	}
+}
+
+func (f VerboseFormatter) renderSection(w io.Writer, title string, lines []string) {
+	if len(lines) == 0 {
+		return
+	}
+	_, _ = io.WriteString(w, "\n"+title)
+	for _, line := range lines {
+		_, _ = w.Write(detailSep)
+		f.paint(w, ansiBold, strings.ReplaceAll(line, "\n", string(detailSep)))
+	}
+}
+
+// paint writes s, wrapped in the ANSI escape code if f.Color is set.
+func (f VerboseFormatter) paint(w io.Writer, code, s string) {
+	if f.Color && s != "" {
+		s = code + s + ansiReset
+	}
+	_, _ = io.WriteString(w, s)
+}
+
+// prettyFormatter is VerboseFormatter with colors, if colorAllowed when
+// rendering. Unlike FprintPretty, it cannot tell whether the output goes
+// to a terminal, as %+v writes to a buffer of fmt.
+type prettyFormatter struct{}
+
+// Render implements Formatter.
+func (prettyFormatter) Render(w io.Writer, r *Report) {
+	VerboseFormatter{Color: colorAllowed()}.Render(w, r)
+}
+
+// CompactFormatter renders one line per layer, with the innermost frame
+// of its stack trace if it has one:
+//
+//	<type>: <message> (at <function> <file>:<line>)
+//
+// or of the stack of the code that spawned its goroutine, for layers added
+// by Go and WithSpawnSite:
+//
+//	<type>: <message> (spawned from <function> <file>:<line>)
+//
+// followed by one line per hint and detail.
+type CompactFormatter struct{}
+
+// Render implements Formatter.
+func (CompactFormatter) Render(w io.Writer, r *Report) {
+	for i, layer := range r.Layers {
+		if i > 0 {
+			_, _ = io.WriteString(w, "\n")
+		}
+		_, _ = fmt.Fprintf(w, "%s: %s", layer.Type, layer.Message)
+		if len(layer.Frames) > 0 {
+			frame := layer.Frames[0]
+			_, _ = fmt.Fprintf(w, " (at %s %s:%d)", frame.Function, frame.File, frame.Line)
+		}
+		if len(layer.SpawnedFrom) > 0 {
+			frame := layer.SpawnedFrom[0]
+			_, _ = fmt.Fprintf(w, " (spawned from %s %s:%d)", frame.Function, frame.File, frame.Line)
+		}
+	}
+	for _, hint := range r.Hints {
+		_, _ = fmt.Fprintf(w, "\nhint: %s", hint)
+	}
+	for _, detail := range r.Details {
+		_, _ = fmt.Fprintf(w, "\ndetail: %s", detail)
+	}
+}
+
+// PkgErrorsFormatter mirrors the %+v output of github.com/pkg/errors:
+// starting from the root cause, each message is followed by the stack
+// trace recorded with it. Messages added by wrapping are printed without
+// the message of the error they wrap.
+type PkgErrorsFormatter struct{}
+
+// Render implements Formatter.
+func (PkgErrorsFormatter) Render(w io.Writer, r *Report) {
+	var prev string
+	for i := len(r.Layers) - 1; i >= 0; i-- {
+		layer := r.Layers[i]
+		if i == len(r.Layers)-1 {
+			_, _ = io.WriteString(w, layer.Message)
+		} else if layer.Message != prev {
+			msg := strings.TrimSuffix(layer.Message, ": "+prev)
+			_, _ = io.WriteString(w, "\n"+msg)
+		}
+		prev = layer.Message
+		for _, frame := range layer.Frames {
+			_, _ = fmt.Fprintf(w, "\n%s\n\t%s:%d", frame.Function, frame.File, frame.Line)
+		}
+	}
+}
+
+// TemplateFormatter renders a Report with a text/template.
+type TemplateFormatter struct {
+	tmpl *template.Template
+}
+
+// NewTemplateFormatter parses text as a text/template that is executed
+// with a *Report. Besides the builtin functions, templates can use
+// "frame" to render a runtime.Frame as "<function> <file>:<line>".
+func NewTemplateFormatter(text string) (*TemplateFormatter, error) {
+	tmpl, err := template.New("error").Funcs(template.FuncMap{
+		"frame": func(f runtime.Frame) string {
+			return f.Function + " " + f.File + ":" + strconv.Itoa(f.Line)
+		},
+	}).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TemplateFormatter{tmpl: tmpl}, nil
+}
+
+// Render implements Formatter.
+func (f *TemplateFormatter) Render(w io.Writer, r *Report) {
+	if err := f.tmpl.Execute(w, r); err != nil {
+		_, _ = fmt.Fprintf(w, "%%!v(TEMPLATE ERROR: %v)", err)
+	}
+}
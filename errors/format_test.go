@@ -0,0 +1,187 @@
+package errors_test
+
+import (
+	stderrors "errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/StevenACoffman/simplerr/errors"
+)
+
+// stacklessChain builds an error chain without stack traces, so that its
+// rendering does not depend on file paths and line numbers.
+func stacklessChain() error {
+	err := errors.With(stderrors.New("connection refused"), stderrors.New("dial"))
+	err = errors.WithHint(err, "is the server running?")
+	return fmt.Errorf("open db: %w", err)
+}
+
+func TestFormatters(t *testing.T) {
+	tests := []struct {
+		name      string
+		formatter errors.Formatter
+		expected  string
+	}{
+		{
+			name:      "verbose",
+			formatter: errors.VerboseFormatter{},
+			expected: `(1) open db: dial: connection refused
+Wraps: (2) dial: connection refused
+Wraps: (3) dial: connection refused
+Wraps: (4) connection refused
+Error types: (1) *fmt.wrapError (2) *errors.withHint (3) *errors.wrapper (4) *errors.errorString
+Hints:
+  | is the server running?`,
+		},
+		{
+			name:      "compact",
+			formatter: errors.CompactFormatter{},
+			expected: `*fmt.wrapError: open db: dial: connection refused
+*errors.withHint: dial: connection refused
+*errors.wrapper: dial: connection refused
+*errors.errorString: connection refused
+hint: is the server running?`,
+		},
+		{
+			name:      "pkgerrors",
+			formatter: errors.PkgErrorsFormatter{},
+			expected: `connection refused
+dial
+open db`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := errors.FormatWith(stacklessChain(), tt.formatter)
+			if actual != tt.expected {
+				t.Fatalf("expected:\n%s\nbut got:\n%s", tt.expected, actual)
+			}
+			registered, ok := errors.LookupFormatter(tt.name)
+			if !ok || registered != tt.formatter {
+				t.Fatalf("expected %s to be registered, got %v", tt.name, registered)
+			}
+		})
+	}
+}
+
+func TestFormatterStacks(t *testing.T) {
+	err := errors.Wrap(io.EOF, "reading config")
+	for _, f := range []errors.Formatter{
+		errors.VerboseFormatter{},
+		errors.CompactFormatter{},
+		errors.PkgErrorsFormatter{},
+	} {
+		if actual := errors.FormatWith(err, f); !strings.Contains(actual, "errors_test.TestFormatterStacks") {
+			t.Errorf("expected %T output to contain the stack trace, got:\n%s", f, actual)
+		}
+	}
+}
+
+func TestTemplateFormatter(t *testing.T) {
+	f, err := errors.NewTemplateFormatter(
+		`{{.Message}}{{range .Layers}}{{if .Frames}} @ {{frame (index .Frames 0)}}{{end}}{{end}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual := errors.FormatWith(errors.New("boom"), f)
+	if !strings.HasPrefix(actual, "boom @ github.com/StevenACoffman/simplerr/errors_test.TestTemplateFormatter ") {
+		t.Fatalf("unexpected template output: %s", actual)
+	}
+	if _, err := errors.NewTemplateFormatter("{{"); err == nil {
+		t.Fatal("expected an invalid template to be rejected")
+	}
+}
+
+func TestDefaultFormatter(t *testing.T) {
+	defer func() {
+		if err := errors.SetDefaultFormatter(errors.FormatterVerbose); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	f, err := errors.NewTemplateFormatter(`custom: {{.Message}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errors.RegisterFormatter("custom", f)
+	if err := errors.SetDefaultFormatter("custom"); err != nil {
+		t.Fatal(err)
+	}
+	if actual := fmt.Sprintf("%+v", errors.New("boom")); actual != "custom: boom" {
+		t.Fatalf("expected the default formatter to be used, got %q", actual)
+	}
+	if err := errors.SetDefaultFormatter("missing"); err == nil {
+		t.Fatal("expected an unknown formatter to be rejected")
+	}
+}
+
+func TestFormatVerbs(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{name: "withStack", err: errors.New("boom")},
+		{name: "withFields", err: errors.WrapWithFields(errors.New("boom"), errors.Fields{"k": "v"})},
+		{name: "wrapper", err: errors.Wrap(io.EOF, "reading")},
+		{
+			name: "mixed",
+			err: errors.WithStack(fmt.Errorf("context: %w",
+				errors.WrapWithFields(errors.Wrap(myError("leaf"), "middle"), errors.Fields{"k": 1}))),
+		},
+		{name: "hint", err: errors.WithHint(errors.New("boom"), "try again")},
+		{name: "detail", err: errors.WithDetail(errors.New("boom"), "it broke")},
+		{name: "mark", err: errors.Mark(errors.New("boom"), NotFound)},
+		{name: "barrier", err: errors.Opaque(errors.New(`"quoted" boom`))},
+		{name: "retryable", err: errors.MarkRetryable(errors.New("boom"))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := tt.err.Error()
+			for format, expected := range map[string]string{
+				"%s":   msg,
+				"%v":   msg,
+				"%q":   strconv.Quote(msg),
+				"%d":   fmt.Sprintf("%%!d(%T=%s)", tt.err, msg),
+				"[%s]": "[" + msg + "]",
+			} {
+				if actual := fmt.Sprintf(format, tt.err); actual != expected {
+					t.Errorf("%s: expected %q but got %q", format, expected, actual)
+				}
+			}
+
+			verbose := fmt.Sprintf("%+v", tt.err)
+			if !strings.HasPrefix(verbose, "(1) "+msg) || !strings.Contains(verbose, "\nError types: (1) ") {
+				t.Errorf("%%+v: unexpected verbose output:\n%s", verbose)
+			}
+
+			goSyntax := fmt.Sprintf("%#v", tt.err)
+			if prefix := "&" + strings.TrimPrefix(fmt.Sprintf("%T", tt.err), "*") + "{"; !strings.HasPrefix(goSyntax, prefix) {
+				t.Errorf("%%#v: expected prefix %s, got %s", prefix, goSyntax)
+			}
+			if strings.Contains(goSyntax, "\n") {
+				t.Errorf("%%#v: unexpected stack trace in %s", goSyntax)
+			}
+		})
+	}
+}
+
+func TestFormatStack(t *testing.T) {
+	st := errors.Callers(1)
+	tests := []struct {
+		format string
+		prefix string
+	}{
+		{format: "%s", prefix: "[format_test.go testing.go"},
+		{format: "%v", prefix: "[format_test.go:"},
+		{format: "%+v", prefix: "\ngithub.com/StevenACoffman/simplerr/errors_test.TestFormatStack\n\t"},
+		{format: "%#v", prefix: "&errors.Stack{0x"},
+	}
+	for _, tt := range tests {
+		if actual := fmt.Sprintf(tt.format, st); !strings.HasPrefix(actual, tt.prefix) {
+			t.Errorf("%s: expected prefix %q, got %q", tt.format, tt.prefix, actual)
+		}
+	}
+}
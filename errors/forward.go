@@ -0,0 +1,190 @@
+package errors
+
+import (
+	"fmt"
+	"sync"
+)
+
+// The wrappers of this package hide the methods of the errors they wrap
+// from type assertions, which libraries that predate errors.As rely on,
+// such as the status.FromError of older versions of grpc-go, or callers
+// checking for a net.Error. To keep them working, WithStack, WrapWithFields,
+// Wrap and Trace return their wrapper extended with the well-known methods
+// of the error they wrap:
+//
+//	Timeout() bool    // net.Error
+//	Temporary() bool  // net.Error
+//	HTTPStatus() int
+//
+// Other interfaces, such as GRPCStatus() *status.Status, which this
+// package cannot name without depending on grpc-go, are forwarded by the
+// functions registered with RegisterForwarder.
+
+// ForwardFunc returns outer, a wrapper of cause, extended with methods of
+// cause, or nil if cause has none of the methods it forwards. The returned
+// error must embed Forwarding{Err: outer}:
+//
+//	type grpcError struct {
+//		errors.Forwarding
+//		cause interface{ GRPCStatus() *status.Status }
+//	}
+//
+//	func (e grpcError) GRPCStatus() *status.Status { return e.cause.GRPCStatus() }
+//
+//	func forwardGRPCStatus(outer, cause error) error {
+//		if s, ok := cause.(interface{ GRPCStatus() *status.Status }); ok {
+//			return grpcError{errors.Forwarding{Err: outer}, s}
+//		}
+//		return nil
+//	}
+type ForwardFunc func(outer, cause error) error
+
+var (
+	forwardersMu sync.RWMutex
+	forwarders   []ForwardFunc
+)
+
+// RegisterForwarder adds f to the functions that extend the wrappers of
+// this package. They are called in the order they were registered, and
+// the first one to return an error wins, instead of the built-in
+// forwarding of well-known methods, so f should also forward those it
+// needs.
+func RegisterForwarder(f ForwardFunc) {
+	forwardersMu.Lock()
+	defer forwardersMu.Unlock()
+	forwarders = append(forwarders, f)
+}
+
+// Forwarding makes an error behave as Err, the wrapper it extends, for
+// formatting and unwrapping. The chain of an error that embeds Forwarding
+// is reported as the one of Err, without an extra layer.
+type Forwarding struct {
+	Err error
+}
+
+// compiler enforced interface conformance checks
+var (
+	_ error         = Forwarding{}
+	_ fmt.Formatter = Forwarding{}
+	_ Unwrapper     = Forwarding{}
+	_ forwarding    = Forwarding{}
+)
+
+func (f Forwarding) Error() string { return f.Err.Error() }
+func (f Forwarding) Unwrap() error { return f.Err }
+
+// Format implements the fmt.Formatter interface.
+func (f Forwarding) Format(st fmt.State, verb rune) {
+	formatError(st, verb, f.Err)
+}
+
+func (f Forwarding) unforwarded() error { return f.Err }
+
+// forwarding is implemented by the errors that embed Forwarding.
+type forwarding interface {
+	unforwarded() error
+}
+
+// unforward returns the wrapper extended by err, if err embeds Forwarding,
+// or err.
+func unforward(err error) error {
+	if f, ok := err.(forwarding); ok {
+		return f.unforwarded()
+	}
+
+	return err
+}
+
+// forward extends outer, a wrapper of cause, with the methods of cause
+// that are forwarded.
+func forward(outer, cause error) error {
+	forwardersMu.RLock()
+	fs := forwarders
+	forwardersMu.RUnlock()
+	for _, f := range fs {
+		if err := f(outer, cause); err != nil {
+			return err
+		}
+	}
+	if err := forwardWellKnown(outer, cause); err != nil {
+		return err
+	}
+
+	return outer
+}
+
+type (
+	timeoutMethod    struct{ cause interface{ Timeout() bool } }
+	temporaryMethod  struct{ cause interface{ Temporary() bool } }
+	httpStatusMethod struct{ cause interface{ HTTPStatus() int } }
+)
+
+func (m timeoutMethod) Timeout() bool      { return m.cause.Timeout() }
+func (m temporaryMethod) Temporary() bool  { return m.cause.Temporary() }
+func (m httpStatusMethod) HTTPStatus() int { return m.cause.HTTPStatus() }
+
+// One type per combination of the well-known methods, so that each
+// extended wrapper has exactly the methods of its cause.
+type (
+	forwardTimeout struct {
+		Forwarding
+		timeoutMethod
+	}
+	forwardTemporary struct {
+		Forwarding
+		temporaryMethod
+	}
+	forwardNetError struct {
+		Forwarding
+		timeoutMethod
+		temporaryMethod
+	}
+	forwardHTTPStatus struct {
+		Forwarding
+		httpStatusMethod
+	}
+	forwardTimeoutHTTPStatus struct {
+		Forwarding
+		timeoutMethod
+		httpStatusMethod
+	}
+	forwardTemporaryHTTPStatus struct {
+		Forwarding
+		temporaryMethod
+		httpStatusMethod
+	}
+	forwardNetErrorHTTPStatus struct {
+		Forwarding
+		timeoutMethod
+		temporaryMethod
+		httpStatusMethod
+	}
+)
+
+// forwardWellKnown extends outer with the well-known methods of cause, or
+// returns nil if cause has none.
+func forwardWellKnown(outer, cause error) error {
+	to, isTimeout := cause.(interface{ Timeout() bool })
+	te, isTemporary := cause.(interface{ Temporary() bool })
+	hs, hasStatus := cause.(interface{ HTTPStatus() int })
+	f := Forwarding{Err: outer}
+	t, p, h := timeoutMethod{to}, temporaryMethod{te}, httpStatusMethod{hs}
+	switch {
+	case isTimeout && isTemporary && hasStatus:
+		return &forwardNetErrorHTTPStatus{f, t, p, h}
+	case isTimeout && isTemporary:
+		return &forwardNetError{f, t, p}
+	case isTimeout && hasStatus:
+		return &forwardTimeoutHTTPStatus{f, t, h}
+	case isTemporary && hasStatus:
+		return &forwardTemporaryHTTPStatus{f, p, h}
+	case isTimeout:
+		return &forwardTimeout{f, t}
+	case isTemporary:
+		return &forwardTemporary{f, p}
+	case hasStatus:
+		return &forwardHTTPStatus{f, h}
+	}
+
+	return nil
+}
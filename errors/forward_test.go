@@ -0,0 +1,127 @@
+package errors_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/StevenACoffman/simplerr/errors"
+	"github.com/StevenACoffman/simplerr/errors/errorstest"
+)
+
+type statusError int
+
+func (e statusError) Error() string   { return fmt.Sprintf("status %d", int(e)) }
+func (e statusError) HTTPStatus() int { return int(e) }
+
+type statusTimeoutError struct{ statusError }
+
+func (statusTimeoutError) Timeout() bool { return true }
+
+// codeError stands for an error of a library this package does not know,
+// like the errors of grpc-go and their GRPCStatus method.
+type codeError struct{}
+
+func (codeError) Error() string { return "unavailable" }
+func (codeError) Code() string  { return "UNAVAILABLE" }
+
+type coder interface{ Code() string }
+
+type forwardCode struct {
+	errors.Forwarding
+	cause coder
+}
+
+func (e forwardCode) Code() string { return e.cause.Code() }
+
+func init() {
+	errors.RegisterForwarder(func(outer, cause error) error {
+		if c, ok := cause.(coder); ok {
+			return forwardCode{errors.Forwarding{Err: outer}, c}
+		}
+		return nil
+	})
+}
+
+func TestForward(t *testing.T) {
+	wrappers := map[string]func(error) error{
+		"WithStack": errors.WithStack,
+		"WrapWithFields": func(err error) error {
+			return errors.WrapWithFields(err, errors.Fields{"k": 1})
+		},
+		"Wrap":  func(err error) error { return errors.Wrap(err, "dialing") },
+		"Trace": errors.Trace,
+		"Trace twice": func(err error) error {
+			return errors.Trace(errors.Trace(err))
+		},
+		"Wrap then Trace": func(err error) error {
+			return errors.Trace(errors.Wrap(errors.WithStack(err), "dialing"))
+		},
+	}
+	for name, wrap := range wrappers {
+		t.Run(name, func(t *testing.T) {
+			ne, ok := wrap(timeoutError{}).(net.Error)
+			if !ok || !ne.Timeout() || ne.Temporary() {
+				t.Errorf("expected a net.Error that timed out, got %#v", ne)
+			}
+			hs, ok := wrap(statusError(404)).(interface{ HTTPStatus() int })
+			if !ok || hs.HTTPStatus() != 404 {
+				t.Errorf("expected an HTTP status of 404, got %#v", hs)
+			}
+			if _, ok := wrap(statusError(404)).(interface{ Timeout() bool }); ok {
+				t.Error("expected only the methods of the cause to be forwarded")
+			}
+			err := wrap(statusTimeoutError{statusError(504)})
+			if to, ok := err.(interface{ Timeout() bool }); !ok || !to.Timeout() {
+				t.Errorf("expected a timeout, got %#v", err)
+			}
+			if hs, ok := err.(interface{ HTTPStatus() int }); !ok || hs.HTTPStatus() != 504 {
+				t.Errorf("expected an HTTP status of 504, got %#v", err)
+			}
+			if c, ok := wrap(codeError{}).(coder); !ok || c.Code() != "UNAVAILABLE" {
+				t.Errorf("expected the registered forwarder to apply, got %#v", c)
+			}
+			if _, ok := wrap(errGone).(net.Error); ok {
+				t.Error("expected no method to be forwarded from a plain error")
+			}
+		})
+	}
+
+	// The deadline errors of the standard library are net.Errors.
+	if ne, ok := errors.WithStack(context.DeadlineExceeded).(net.Error); !ok || !ne.Timeout() {
+		t.Errorf("expected context.DeadlineExceeded to stay a net.Error")
+	}
+}
+
+func TestForwardChain(t *testing.T) {
+	err := errors.WrapWithFields(errors.WithStack(timeoutError{}), errors.Fields{"k": 1})
+	errorstest.AssertIs(t, err, timeoutError{})
+	errorstest.AssertAs[timeoutError](t, err)
+	errorstest.AssertFields(t, err, errors.Fields{"k": 1})
+	errorstest.AssertStackContains(t, err, "errors_test.TestForwardChain")
+	// The forwarding adds no layer.
+	errorstest.AssertChainTypes(t, err, "*errors.withFields", "*errors.withStack", "errors_test.timeoutError")
+	var walked []string
+	errors.Walk(err, func(e error, depth int, _ []int) bool {
+		walked = append(walked, fmt.Sprintf("%d:%T", depth, e))
+		return true
+	})
+	if actual := strings.Join(walked, " "); actual != "0:*errors.withFields 1:*errors.withStack 2:errors_test.timeoutError" {
+		t.Errorf("unexpected walk %s", actual)
+	}
+	if depth := errors.Depth(err); depth != 3 {
+		t.Errorf("expected a depth of 3, got %d", depth)
+	}
+	if actual := fmt.Sprintf("%+v", err); !strings.HasSuffix(actual,
+		"Error types: (1) *errors.withFields (2) *errors.withStack (3) errors_test.timeoutError") {
+		t.Errorf("unexpected verbose output:\n%s", actual)
+	}
+	if actual := fmt.Sprint(err); actual != "Fields: [k:1], Cause: i/o timeout" {
+		t.Errorf("unexpected message %q", actual)
+	}
+	if root := errors.UnwrapAll(err); root != (timeoutError{}) { //errlint:ignore the root cause itself
+		t.Errorf("expected the root cause, got %#v", root)
+	}
+}
@@ -0,0 +1,105 @@
+package errors_test
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/StevenACoffman/simplerr/errors"
+	"github.com/StevenACoffman/simplerr/errors/errorstest"
+)
+
+func TestGolden(t *testing.T) {
+	tests := []struct {
+		name string
+		err  func() error
+	}{
+		{name: "withstack", err: func() error { return errors.WithStack(myError("boom")) }},
+		{name: "withstack_twice", err: func() error { return errors.WithStack(errors.New("boom")) }}, //errlint:ignore the rendering of a redundant stack
+		{name: "withfields", err: func() error {
+			return errors.WrapWithFields(myError("boom"), errors.Fields{"user": "bob", "attempt": 2})
+		}},
+		{name: "wrapper", err: func() error { return errors.With(io.EOF, myError("reading")) }},
+		{name: "wrap", err: func() error { return errors.Wrap(errors.New("connection refused"), "dial") }},
+		{name: "trace", err: func() error { return errors.Trace(traceService()) }},
+		{name: "mixed", err: func() error {
+			err := errors.WrapWithFields(errors.Wrap(myError("leaf"), "middle"), errors.Fields{"k": 1})
+			return errors.WithHint(fmt.Errorf("context: %w", err), "try again")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errorstest.AssertGolden(t, tt.name, fmt.Sprintf("%+v", tt.err()))
+		})
+	}
+}
+
+func TestWithFieldsErrList(t *testing.T) {
+	one := fmt.Errorf("one")
+	two := errors.WrapWithFields(one, errors.Fields{"b": "true", "msg": "two"})
+	three := errors.WrapWithFields(two, errors.Fields{"c": "true", "msg": "three"})
+	four := errors.WrapWithFields(three, errors.Fields{"d": "true", "msg": "four"})
+	five := errors.WrapWithFields(four, errors.Fields{"e": "true", "msg": "five"})
+	six := errors.WrapWithFields(five, errors.Fields{"f": "true", "msg": "six"})
+	errorstest.AssertGolden(t, "withfields_err_list", six.Error())
+}
+
+func TestWithFieldsErrAllList(t *testing.T) {
+	one := fmt.Errorf("one")
+	two := errors.WrapWithFields(one, errors.Fields{"b": "true", "msg": "two"})
+	three := errors.WrapWithFields(two, errors.Fields{"c": "true", "msg": "three"})
+	errorstest.AssertGolden(t, "withfields_err_all_list", fmt.Sprintf("%+v", three))
+}
+
+// readmeExample matches the output of the example in the README.
+var readmeExample = regexp.MustCompile("(?s)(\\$ go run -trimpath main.go\n)(.*?)(\n```)")
+
+// TestReadmeExample runs the program of the _example directory, and checks
+// that its output is the one shown in the README.
+func TestReadmeExample(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the go command")
+	}
+	goCmd, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("the go command is not available")
+	}
+	cmd := exec.Command(goCmd, "run", "-trimpath", "main.go")
+	cmd.Dir = "../_example"
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("running the example: %v\n%s", err, out)
+	}
+	if !errorstest.AssertGolden(t, "readme_example", strings.TrimSpace(string(out))) {
+		return
+	}
+
+	golden, err := os.ReadFile(errorstest.GoldenPath("readme_example"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, readme := range []string{"../README.md", "../_example/README.md"} {
+		content, err := os.ReadFile(readme)
+		if err != nil {
+			t.Fatal(err)
+		}
+		updated := readmeExample.ReplaceAllStringFunc(string(content), func(block string) string {
+			m := readmeExample.FindStringSubmatch(block)
+			return m[1] + strings.TrimSuffix(string(golden), "\n") + m[3]
+		})
+		if updated == string(content) {
+			continue
+		}
+		if !errorstest.Updating() {
+			t.Errorf("the example output in %s is out of date, run the test with -errorstest.update", readme)
+			continue
+		}
+		if err := os.WriteFile(readme, []byte(updated), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package errors
+
+import "fmt"
+
+// This file provides hints and details: user-facing annotations that do
+// not change the message returned by Error(), but are surfaced in their
+// own sections of the verbose (%+v) rendering and can be retrieved with
+// GetAllHints and GetAllDetails.
+//
+// A hint is actionable advice for the reader ("try --force").
+// A detail is additional context about what happened ("config was
+// loaded from /etc/app.yaml").
+
+// WithHint decorates err with a user-facing hint.
+// If err is nil, WithHint returns nil.
+func WithHint(err error, msg string) error {
+	if err == nil {
+		return nil
+	}
+
+	return &withHint{cause: err, hint: msg}
+}
+
+// WithHintf is like WithHint, but formats the hint.
+func WithHintf(err error, format string, args ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+
+	return &withHint{cause: err, hint: fmt.Sprintf(format, args...)}
+}
+
+// WithDetail decorates err with a user-facing detail.
+// If err is nil, WithDetail returns nil.
+func WithDetail(err error, msg string) error {
+	if err == nil {
+		return nil
+	}
+
+	return &withDetail{cause: err, detail: msg}
+}
+
+// WithDetailf is like WithDetail, but formats the detail.
+func WithDetailf(err error, format string, args ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+
+	return &withDetail{cause: err, detail: fmt.Sprintf(format, args...)}
+}
+
+// GetAllHints retrieves the hints from err and all its causes, including
+// every branch of multi-errors, outermost first. Duplicate hints are
+// only reported once.
+func GetAllHints(err error) []string {
+	return collectAnnotations(err, func(e error) (string, bool) {
+		if h, ok := e.(*withHint); ok {
+			return h.hint, true
+		}
+		return "", false
+	})
+}
+
+// GetAllDetails retrieves the details from err and all its causes,
+// including every branch of multi-errors, outermost first. Duplicate
+// details are only reported once.
+func GetAllDetails(err error) []string {
+	return collectAnnotations(err, func(e error) (string, bool) {
+		if d, ok := e.(*withDetail); ok {
+			return d.detail, true
+		}
+		return "", false
+	})
+}
+
+// collectAnnotations visits every error reachable from err and returns
+// the deduplicated strings extracted by get.
+func collectAnnotations(err error, get func(error) (string, bool)) []string {
+	var result []string
+	seen := map[string]struct{}{}
+	Walk(err, func(e error, _ int, _ []int) bool {
+		if s, ok := get(e); ok {
+			if _, dup := seen[s]; !dup {
+				seen[s] = struct{}{}
+				result = append(result, s)
+			}
+		}
+		return true
+	})
+
+	return result
+}
+
+type withHint struct {
+	cause error
+	hint  string
+}
+
+// compiler enforced interface conformance checks
+var (
+	_ error          = (*withHint)(nil)
+	_ fmt.Formatter  = (*withHint)(nil)
+	_ fmt.GoStringer = (*withHint)(nil)
+	_ Unwrapper      = (*withHint)(nil)
+)
+
+func (w *withHint) Error() string { return w.cause.Error() }
+func (w *withHint) Cause() error  { return w.cause }
+func (w *withHint) Unwrap() error { return w.cause }
+
+// Format implements the fmt.Formatter interface.
+func (w *withHint) Format(st fmt.State, verb rune) {
+	formatError(st, verb, w)
+}
+
+// GoString implements the fmt.GoStringer interface, for %#v.
+func (w *withHint) GoString() string {
+	return fmt.Sprintf("&errors.withHint{cause:%#v, hint:%q}", w.cause, w.hint)
+}
+
+type withDetail struct {
+	cause  error
+	detail string
+}
+
+// compiler enforced interface conformance checks
+var (
+	_ error          = (*withDetail)(nil)
+	_ fmt.Formatter  = (*withDetail)(nil)
+	_ fmt.GoStringer = (*withDetail)(nil)
+	_ Unwrapper      = (*withDetail)(nil)
+)
+
+func (w *withDetail) Error() string { return w.cause.Error() }
+func (w *withDetail) Cause() error  { return w.cause }
+func (w *withDetail) Unwrap() error { return w.cause }
+
+// Format implements the fmt.Formatter interface.
+func (w *withDetail) Format(st fmt.State, verb rune) {
+	formatError(st, verb, w)
+}
+
+// GoString implements the fmt.GoStringer interface, for %#v.
+func (w *withDetail) GoString() string {
+	return fmt.Sprintf("&errors.withDetail{cause:%#v, detail:%q}", w.cause, w.detail)
+}
@@ -0,0 +1,61 @@
+package errors_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/StevenACoffman/simplerr/errors"
+)
+
+func TestHintsAndDetails(t *testing.T) {
+	err := errors.New("connection refused")
+	err = errors.WithHint(err, "is the server running?")
+	err = errors.WithDetail(err, "dialing 127.0.0.1:5432")
+	err = errors.Wrap(err, "cannot open database")
+	err = errors.WithHint(err, "is the server running?")
+	err = errors.WithHint(err, "check DATABASE_URL")
+
+	if actual, expected := err.Error(), "cannot open database: connection refused"; actual != expected {
+		t.Fatalf("expected %q but got %q", expected, actual)
+	}
+
+	hints := errors.GetAllHints(err)
+	expectedHints := []string{"check DATABASE_URL", "is the server running?"}
+	if !reflect.DeepEqual(hints, expectedHints) {
+		t.Fatalf("expected hints %q but got %q", expectedHints, hints)
+	}
+
+	details := errors.GetAllDetails(err)
+	expectedDetails := []string{"dialing 127.0.0.1:5432"}
+	if !reflect.DeepEqual(details, expectedDetails) {
+		t.Fatalf("expected details %q but got %q", expectedDetails, details)
+	}
+
+	verbose := fmt.Sprintf("%+v", err)
+	expectedSections := "\nHints:\n  | check DATABASE_URL\n  | is the server running?" +
+		"\nDetails:\n  | dialing 127.0.0.1:5432"
+	if !strings.HasSuffix(verbose, expectedSections) {
+		t.Fatalf("expected verbose output to end with:\n%s\nbut got:\n%s", expectedSections, verbose)
+	}
+}
+
+func TestHintsMultiError(t *testing.T) {
+	one := errors.WithHint(errors.New("one"), "first")
+	two := errors.WithHint(errors.New("two"), "second")
+	three := errors.WithHint(errors.New("three"), "first")
+	err := fmt.Errorf("%w; %w; %w", one, two, three)
+
+	hints := errors.GetAllHints(err)
+	expected := []string{"first", "second"}
+	if !reflect.DeepEqual(hints, expected) {
+		t.Fatalf("expected hints %q but got %q", expected, hints)
+	}
+	if hints := errors.GetAllHints(errors.New("none")); len(hints) != 0 {
+		t.Fatalf("expected no hints but got %q", hints)
+	}
+	if errors.WithHint(nil, "hint") != nil {
+		t.Fatal("expected WithHint(nil) to be nil")
+	}
+}
@@ -0,0 +1,64 @@
+// Package buildid reads the Go build ID of ELF binaries, for the encoded
+// stacks of the errors package and their symbolization by errsym.
+package buildid
+
+import (
+	"bytes"
+	"debug/elf"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// goBuildIDNote is the ELF section holding the Go build ID.
+const goBuildIDNote = ".note.go.buildid"
+
+var (
+	selfOnce sync.Once
+	self     string
+)
+
+// Self returns the Go build ID of the running binary, or "" if it cannot
+// be read, as on platforms other than ELF ones. It is read once.
+func Self() string {
+	selfOnce.Do(func() {
+		path, err := os.Executable()
+		if err != nil {
+			return
+		}
+		f, err := elf.Open(path)
+		if err != nil {
+			return
+		}
+		defer f.Close()
+		self, _ = Read(f)
+	})
+
+	return self
+}
+
+// Read returns the Go build ID stored in the ELF note of f.
+func Read(f *elf.File) (string, error) {
+	section := f.Section(goBuildIDNote)
+	if section == nil {
+		return "", errors.New("no " + goBuildIDNote + " section")
+	}
+	data, err := section.Data()
+	if err != nil {
+		return "", fmt.Errorf("reading %s: %w", goBuildIDNote, err)
+	}
+	// An ELF note is a name size, a descriptor size and a type, each
+	// 4 bytes, then the name ("Go\x00\x00") and the descriptor.
+	if len(data) < 16 {
+		return "", errors.New(goBuildIDNote + " is too short")
+	}
+	nameSize := f.ByteOrder.Uint32(data[0:4])
+	descSize := f.ByteOrder.Uint32(data[4:8])
+	desc := 16
+	if nameSize != 4 || !bytes.Equal(data[12:16], []byte("Go\x00\x00")) || desc+int(descSize) > len(data) {
+		return "", errors.New("malformed " + goBuildIDNote)
+	}
+
+	return string(data[desc : desc+int(descSize)]), nil
+}
@@ -0,0 +1,8 @@
+// Package keytest declares keyed sentinels outside of the tests of the
+// errors package, which check that they are scoped to their package.
+package keytest
+
+import "github.com/StevenACoffman/simplerr/errors"
+
+// ErrNotFound has the text of a keyed sentinel of the tests.
+var ErrNotFound = errors.KeyedSentinel("not found")
@@ -0,0 +1,143 @@
+package errors
+
+import (
+	"fmt"
+	reflectlite "reflect"
+	"runtime"
+	"strings"
+)
+
+// Errors loaded from a Go plugin, or decoded from JSON, are not the
+// sentinels they stand for, so Is does not match them by identity. A
+// sentinel can opt in to being matched by key instead, when the identity
+// comparison fails: Is(err, reference) then also returns true if an error
+// in err's chain has the key of reference.
+
+// keyer is implemented by the errors that have an explicit key.
+type keyer interface {
+	ErrorKey() string
+}
+
+// KeyedSentinel is like Sentinel, but Is also matches the returned error
+// with any error that has the same key. The key is derived from the
+// package that declares the sentinel and text, so that the copy of the
+// sentinel in a plugin built from the same package has the same key, and
+// keyed sentinels with the same text in different packages do not match:
+//
+//	var ErrNotFound = errors.KeyedSentinel("not found")
+func KeyedSentinel(text string) error {
+	pkg := ""
+	if pc, _, _, ok := runtime.Caller(1); ok {
+		if fn := runtime.FuncForPC(pc); fn != nil {
+			pkg = packagePath(fn.Name())
+		}
+	}
+
+	return &keyedSentinel{msg: text, key: pkg + ": " + text}
+}
+
+// SentinelWithID is like KeyedSentinel, with the explicit key id. An error
+// decoded from an encoding of the sentinel can be compared to it with
+// Is by giving it the same key:
+//
+//	var ErrNotFound = errors.SentinelWithID("user.not_found", "not found")
+//
+//	decoded := errors.SentinelWithID(payload.ID, payload.Message)
+//	errors.Is(decoded, ErrNotFound) // true
+func SentinelWithID(id, text string) error {
+	return &keyedSentinel{msg: text, key: id}
+}
+
+type keyedSentinel struct {
+	msg string
+	key string
+}
+
+// compiler enforced interface conformance checks
+var (
+	_ error          = (*keyedSentinel)(nil)
+	_ fmt.Formatter  = (*keyedSentinel)(nil)
+	_ fmt.GoStringer = (*keyedSentinel)(nil)
+	_ Iser           = (*keyedSentinel)(nil)
+	_ keyer          = (*keyedSentinel)(nil)
+)
+
+func (s *keyedSentinel) Error() string    { return s.msg }
+func (s *keyedSentinel) ErrorKey() string { return s.key }
+
+// Is implements the interface needed for errors.Is, so that keyed
+// sentinels also match by key with the Is of the standard library.
+func (s *keyedSentinel) Is(target error) bool {
+	k, ok := target.(keyer)
+	return ok && k.ErrorKey() == s.key
+}
+
+// Format implements the fmt.Formatter interface.
+func (s *keyedSentinel) Format(st fmt.State, verb rune) {
+	formatError(st, verb, s)
+}
+
+// GoString implements the fmt.GoStringer interface, for %#v.
+func (s *keyedSentinel) GoString() string {
+	return fmt.Sprintf("&errors.keyedSentinel{msg:%q, key:%q}", s.msg, s.key)
+}
+
+// ErrorKey returns the key Is compares with the one of a keyed sentinel:
+// the result of the ErrorKey() string method of err if it has one, and
+// otherwise its Go type, with its package path, and its message, as in
+// "*github.com/org/pkg.Error: not found". It returns "" for a nil error.
+func ErrorKey(err error) string {
+	if err == nil {
+		return ""
+	}
+	if k, ok := err.(keyer); ok {
+		return k.ErrorKey()
+	}
+
+	return typeName(err) + ": " + err.Error()
+}
+
+// hasKey reports whether err has the given key. Only the errors that have
+// an explicit key, and the leaves of a chain, are compared: the key of a
+// wrapper would include the message of the errors it wraps.
+func hasKey(err error, key string) bool {
+	if k, ok := err.(keyer); ok {
+		return k.ErrorKey() == key
+	}
+	if UnwrapOnce(err) != nil {
+		return false
+	}
+	if _, ok := err.(interface{ Unwrap() []error }); ok {
+		return false
+	}
+
+	return ErrorKey(err) == key
+}
+
+// typeName returns the name of the type of err, qualified with its whole
+// package path.
+func typeName(err error) string {
+	t := reflectlite.TypeOf(err)
+	ptr := ""
+	for t.Kind() == reflectlite.Ptr {
+		ptr += "*"
+		t = t.Elem()
+	}
+	if t.Name() == "" || t.PkgPath() == "" {
+		return ptr + t.String()
+	}
+
+	return ptr + t.PkgPath() + "." + t.Name()
+}
+
+// packagePath returns the package path of a function name, as returned by
+// runtime.FuncForPC, such as "github.com/org/pkg" for
+// "github.com/org/pkg.init".
+func packagePath(function string) string {
+	slash := strings.LastIndex(function, "/")
+	if dot := strings.Index(function[slash+1:], "."); dot >= 0 {
+		return function[:slash+1+dot]
+	}
+
+	return function
+}
@@ -0,0 +1,96 @@
+package errors_test
+
+import (
+	"encoding/json"
+	stderrs "errors"
+	"fmt"
+	"testing"
+
+	"github.com/StevenACoffman/simplerr/errors"
+	"github.com/StevenACoffman/simplerr/errors/internal/keytest"
+)
+
+var (
+	errKeyedNotFound = errors.KeyedSentinel("not found")
+	errNotFoundID    = errors.SentinelWithID("user.not_found", "not found")
+)
+
+// pluginNotFound stands for the copy of errKeyedNotFound in a plugin built
+// from this package: a distinct error, with the same text.
+func pluginNotFound() error {
+	return errors.KeyedSentinel("not found")
+}
+
+func TestKeyedSentinel(t *testing.T) {
+	copied := pluginNotFound()
+	if copied == errKeyedNotFound { //errlint:ignore a copy, not the sentinel
+		t.Fatal("expected a distinct error")
+	}
+	if key := errors.ErrorKey(errKeyedNotFound); key != "github.com/StevenACoffman/simplerr/errors_test: not found" {
+		t.Fatalf("unexpected key %q", key)
+	}
+
+	tests := []struct {
+		name      string
+		err       error
+		reference error
+		expected  bool
+	}{
+		{name: "identity", err: errors.WithStack(errKeyedNotFound), reference: errKeyedNotFound, expected: true},
+		{name: "copy", err: errors.Wrap(copied, "loading user"), reference: errKeyedNotFound, expected: true},
+		{name: "decoded", err: keyOf(errKeyedNotFound), reference: errKeyedNotFound, expected: true},
+		{name: "marked", err: errors.Mark(errors.New("i/o"), errKeyedNotFound), reference: errKeyedNotFound, expected: true},
+		{name: "other package", err: errors.WithStack(keytest.ErrNotFound), reference: errKeyedNotFound},
+		{name: "other text", err: errors.KeyedSentinel("gone"), reference: errKeyedNotFound},
+		{name: "not keyed", err: errors.Sentinel("not found"), reference: errKeyedNotFound},
+		{name: "not keyed reference", err: errors.Sentinel("not found"), reference: errors.Sentinel("not found")},
+		{name: "other key", err: errNotFoundID, reference: errKeyedNotFound},
+		{name: "leaf", err: fmt.Errorf("ctx: %w", myError("boom")), reference: keyOf(myError("boom")), expected: true},
+		{name: "wrapper", err: fmt.Errorf("ctx: %w", myError("boom")), reference: keyOf(fmt.Errorf("ctx: %w", myError("boom")))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := errors.Is(tt.err, tt.reference); actual != tt.expected {
+				t.Errorf("expected Is(%v, %v) to be %t", tt.err, tt.reference, tt.expected)
+			}
+		})
+	}
+}
+
+// keyOf returns a keyed sentinel with the key of err.
+func keyOf(err error) error {
+	return errors.SentinelWithID(errors.ErrorKey(err), err.Error())
+}
+
+type payload struct {
+	ID      string `json:"id"`
+	Message string `json:"message"`
+}
+
+func TestSentinelWithID(t *testing.T) {
+	data, err := json.Marshal(payload{ID: errors.ErrorKey(errNotFoundID), Message: errNotFoundID.Error()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var p payload
+	if err := json.Unmarshal(data, &p); err != nil {
+		t.Fatal(err)
+	}
+	decoded := errors.WithStack(errors.SentinelWithID(p.ID, p.Message))
+	if !errors.Is(decoded, errNotFoundID) {
+		t.Errorf("expected %v to match by key", decoded)
+	}
+	// Keyed sentinels have an Is method for the standard library.
+	if !stderrs.Is(decoded, errNotFoundID) {
+		t.Errorf("expected %v to match by key with the standard library", decoded)
+	}
+	if errors.Is(decoded, errors.SentinelWithID("user.gone", "not found")) {
+		t.Error("expected different IDs not to match")
+	}
+	if errors.ErrorKey(nil) != "" {
+		t.Error("expected no key for nil")
+	}
+	if actual := fmt.Sprintf("%#v", errNotFoundID); actual != `&errors.keyedSentinel{msg:"not found", key:"user.not_found"}` {
+		t.Errorf("unexpected %%#v %s", actual)
+	}
+}
@@ -0,0 +1,61 @@
+package errors
+
+import "fmt"
+
+// Mark wraps err so that Is(err, reference) returns true, in addition to
+// anything err already matched. The original error remains in the chain,
+// so its message, stack traces and fields are preserved.
+//
+// This is useful to map an error from a dependency onto one of our own
+// sentinels, for example:
+//
+//	if err == sql.ErrNoRows {
+//		return errors.Mark(err, ErrNotFound)
+//	}
+//
+// If err is nil, Mark returns nil.
+func Mark(err, reference error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &withMark{cause: err, mark: reference}
+}
+
+type withMark struct {
+	cause error
+	mark  error
+}
+
+// compiler enforced interface conformance checks
+var (
+	_ error          = (*withMark)(nil)
+	_ fmt.Formatter  = (*withMark)(nil)
+	_ fmt.GoStringer = (*withMark)(nil)
+	_ Iser           = (*withMark)(nil)
+	_ Unwrapper      = (*withMark)(nil)
+)
+
+func (w *withMark) Error() string { return w.cause.Error() }
+func (w *withMark) Cause() error  { return w.cause }
+func (w *withMark) Unwrap() error { return w.cause }
+
+// Is implements the interface needed for errors.Is. It only checks the
+// mark; the cause is checked by Is when it unwraps this error.
+func (w *withMark) Is(target error) bool {
+	if w.mark == nil || target == nil {
+		return false
+	}
+
+	return Is(w.mark, target)
+}
+
+// Format implements the fmt.Formatter interface.
+func (w *withMark) Format(st fmt.State, verb rune) {
+	formatError(st, verb, w)
+}
+
+// GoString implements the fmt.GoStringer interface, for %#v.
+func (w *withMark) GoString() string {
+	return fmt.Sprintf("&errors.withMark{cause:%#v, mark:%#v}", w.cause, w.mark)
+}
@@ -0,0 +1,74 @@
+package errors_test
+
+import (
+	stderrors "errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/StevenACoffman/simplerr/errors"
+)
+
+func TestMark(t *testing.T) {
+	driverErr := myError("no rows in result set")
+	err := errors.Mark(errors.WithStack(driverErr), NotFound)
+
+	if !errors.Is(err, NotFound) {
+		t.Fatal("failed to match mark")
+	}
+	if !stderrors.Is(err, NotFound) {
+		t.Fatal("failed to match mark with the standard library")
+	}
+	if !errors.Is(err, driverErr) {
+		t.Fatal("failed to match original error")
+	}
+	var my myError
+	if !errors.As(err, &my) {
+		t.Fatal("failed to find original type")
+	}
+	if errors.Is(err, io.EOF) {
+		t.Fatal("unexpectedly matched unrelated error")
+	}
+	if actual, expected := err.Error(), driverErr.Error(); actual != expected {
+		t.Fatalf("expected %q but got %q", expected, actual)
+	}
+	if errors.Mark(nil, NotFound) != nil {
+		t.Fatal("expected Mark(nil) to be nil")
+	}
+}
+
+func TestBarriers(t *testing.T) {
+	for name, barrier := range map[string]func(error) error{
+		"Handled": errors.Handled,
+		"Opaque":  errors.Opaque,
+	} {
+		t.Run(name, func(t *testing.T) {
+			inner := errors.Wrap(myError("internal"), "lookup failed")
+			err := errors.Wrap(barrier(inner), "api")
+
+			if errors.Is(err, inner) || stderrors.Is(err, inner) {
+				t.Fatal("barrier did not stop Is")
+			}
+			var my myError
+			if errors.As(err, &my) || stderrors.As(err, &my) {
+				t.Fatal("barrier did not stop As")
+			}
+			if actual, expected := err.Error(), "api: lookup failed: internal"; actual != expected {
+				t.Fatalf("expected %q but got %q", expected, actual)
+			}
+			if cause := errors.Cause(err); cause.Error() != inner.Error() || errors.UnwrapOnce(cause) != nil {
+				t.Fatalf("expected Cause to stop at the barrier, got %#v", cause)
+			}
+
+			verbose := fmt.Sprintf("%+v", errors.WithStack(err))
+			if !strings.Contains(verbose, "cause hidden behind barrier:") ||
+				!strings.Contains(verbose, "errors_test.TestBarriers") {
+				t.Fatalf("expected verbose output to show the hidden cause, got:\n%s", verbose)
+			}
+			if barrier(nil) != nil {
+				t.Fatalf("expected %s(nil) to be nil", name)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package errors
+
+import (
+	"fmt"
+	"runtime"
+
+	"golang.org/x/xerrors"
+)
+
+// Report is a structured view of an error chain. It is what a Formatter
+// renders, so that the same error can be laid out in different ways.
+type Report struct {
+	// Err is the error the report was built from. It is nil for reports
+	// that were not built from a live error value.
+	Err error
+	// Message is the message of the whole chain, as returned by Error().
+	Message string
+	// Layers holds one entry per error in the chain, outermost first.
+	Layers []Layer
+	// Hints and Details are the deduplicated hints and details attached
+	// anywhere in the chain, as returned by GetAllHints and GetAllDetails.
+	Hints   []string
+	Details []string
+	// ReturnTrace holds the frames of the return sites the error went
+	// through, as recorded by Trace and Wrap, in that order.
+	ReturnTrace []runtime.Frame
+}
+
+// Layer is a single error of a chain.
+type Layer struct {
+	// Err is the error at this level. It is nil for reports that were not
+	// built from a live error value.
+	Err error
+	// Message is the message of this error, as returned by Error().
+	Message string
+	// Type is the Go type of this error, as printed by %T.
+	Type string
+	// Fields are the fields attached at this level only.
+	Fields Fields
+	// Frames is the stack trace recorded at this level, innermost first,
+	// without the final runtime.main or runtime.goexit frame.
+	Frames []runtime.Frame
+	// ElidedFrames is true when the outermost frames of this stack trace
+	// were dropped because they are the same as in a deeper layer.
+	ElidedFrames bool
+	// Masked is the report of the error hidden behind a barrier at this
+	// level (see Handled and Opaque), if any.
+	Masked *Report
+	// SpawnedFrom is the stack of the code that spawned the goroutine the
+	// error was produced in (see Go and WithSpawnSite), innermost first.
+	SpawnedFrom []runtime.Frame
+	// Detail holds the lines printed as detail by the FormatError method of
+	// errors implementing golang.org/x/xerrors.Formatter, except for a
+	// location printed by xerrors.Frame, which is in Frames.
+	Detail []string
+}
+
+// NewReport builds the Report of err.
+func NewReport(err error) *Report {
+	if err == nil {
+		return &Report{}
+	}
+	entries := getEntries(err)
+	layers := make([]Layer, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		layers = append(layers, newLayer(entries[i]))
+	}
+
+	return &Report{
+		Err:         err,
+		Message:     err.Error(),
+		Layers:      layers,
+		Hints:       GetAllHints(err),
+		Details:     GetAllDetails(err),
+		ReturnTrace: returnTrace(err),
+	}
+}
+
+// returnTrace returns the frames of the return trace of err, from the
+// innermost hop out. The hops are held by the layers added by Trace, which
+// are not listed in the report, and by the messages added by Wrap.
+func returnTrace(err error) []runtime.Frame {
+	var pcs []uintptr
+	for err != nil {
+		var hops []uintptr
+		switch e := err.(type) {
+		case *withStack:
+			hops = e.returnTrace
+		case *wrapper:
+			if f, ok := e.front.(*withStack); ok {
+				hops = f.returnTrace
+			}
+		}
+		if len(hops) > 0 {
+			pcs = append(append([]uintptr(nil), hops...), pcs...)
+		}
+		// As in getEntries, a wrapper is followed by its back error.
+		if w, ok := err.(*wrapper); ok {
+			err = w.back
+			continue
+		}
+		err = UnwrapOnce(err)
+	}
+	if len(pcs) == 0 {
+		return nil
+	}
+
+	return hopFrames(pcs)
+}
+
+func newLayer(err error) Layer {
+	layer := Layer{Err: err, Message: err.Error(), Type: fmt.Sprintf("%T", err)}
+	switch e := err.(type) {
+	case *withStack:
+		layer.Frames = stackFrames(e.Stack, e.hasSkippedFrames)
+		layer.ElidedFrames = e.hasSkippedFrames
+	case *withFields:
+		layer.Fields = e.fields
+		layer.Frames = stackFrames(e.Stack, e.hasSkippedFrames)
+		layer.ElidedFrames = e.hasSkippedFrames
+	case *wrapper:
+		// wrapper.Unwrap skips over the front error itself, so the stack
+		// it carries, such as the one recorded by Wrap, is shown here.
+		switch f := e.front.(type) {
+		case *withStack:
+			layer.Frames = stackFrames(f.Stack, f.hasSkippedFrames)
+			layer.ElidedFrames = f.hasSkippedFrames
+		case *withFields:
+			layer.Frames = stackFrames(f.Stack, f.hasSkippedFrames)
+			layer.ElidedFrames = f.hasSkippedFrames
+		}
+	case *barrier:
+		layer.Masked = NewReport(e.masked)
+	case *withSpawnSite:
+		layer.SpawnedFrom = stackFrames(e.site, false)
+	case xerrors.Formatter:
+		layer.Frames, layer.Detail = formatErrorDetail(e)
+	}
+
+	return layer
+}
+
+// stackFrames returns the frames of s, without the final runtime.main or
+// runtime.goexit frame. Stacks whose shared suffix was elided do not end
+// with such a frame, so all of their frames are returned.
+func stackFrames(s *Stack, elided bool) []runtime.Frame {
+	if s == nil || len(*s) == 0 {
+		return nil
+	}
+	var frames []runtime.Frame
+	st := s.StackTrace()
+	for {
+		frame, more := st.Next()
+		frames = append(frames, frame)
+		if !more {
+			break
+		}
+	}
+	if !elided {
+		// As in FormatStack, the last frame is dropped.
+		frames = frames[:len(frames)-1]
+	}
+
+	return frames
+}
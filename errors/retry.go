@@ -0,0 +1,216 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// This file classifies errors as retryable, timeouts or temporary
+// failures, so that job runners don't have to match on error strings.
+//
+// Explicit markers (MarkRetryable, MarkNonRetryable, or any error in the
+// chain with a Retryable() bool method) always win. Otherwise registered
+// classifiers are consulted, and finally timeouts and temporary failures
+// are considered retryable.
+
+// MarkRetryable marks err as safe to retry.
+// If err is nil, MarkRetryable returns nil.
+func MarkRetryable(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &withRetryable{cause: err, retryable: true}
+}
+
+// MarkNonRetryable marks err as not safe to retry, even if it is a
+// timeout or a temporary failure.
+// If err is nil, MarkNonRetryable returns nil.
+func MarkNonRetryable(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &withRetryable{cause: err, retryable: false}
+}
+
+// A RetryClassifier reports whether err is retryable. It returns ok=false
+// when it has no opinion about err.
+type RetryClassifier func(err error) (retryable, ok bool)
+
+var (
+	classifiersMu sync.RWMutex
+	classifiers   []RetryClassifier
+)
+
+// RegisterRetryClassifier adds c to the classifiers consulted by
+// IsRetryable. Classifiers are consulted in registration order, after
+// explicit markers and before the timeout and temporary checks.
+func RegisterRetryClassifier(c RetryClassifier) {
+	classifiersMu.Lock()
+	defer classifiersMu.Unlock()
+	classifiers = append(classifiers, c)
+}
+
+// IsRetryable reports whether err is worth retrying.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var marker interface{ Retryable() bool }
+	if As(err, &marker) {
+		return marker.Retryable()
+	}
+
+	classifiersMu.RLock()
+	cs := classifiers
+	classifiersMu.RUnlock()
+	for _, c := range cs {
+		if retryable, ok := c(err); ok {
+			return retryable
+		}
+	}
+
+	return IsTimeout(err) || IsTemporary(err)
+}
+
+// IsTimeout reports whether err is a timeout: either
+// context.DeadlineExceeded, or an error in the chain with a Timeout()
+// bool method that returns true, such as net.Error or syscall.Errno. The
+// whole chain is searched, so a deeper timeout is found even if an outer
+// error reports false.
+func IsTimeout(err error) bool {
+	if err == nil {
+		return false
+	}
+	if Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	for _, t := range FindAll[interface{ Timeout() bool }](err) {
+		if t.Timeout() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsTemporary reports whether err is a temporary failure: an error in the
+// chain with a Temporary() bool method that returns true, such as
+// syscall.Errno for EINTR or EAGAIN. As for IsTimeout, the whole chain is
+// searched.
+func IsTemporary(err error) bool {
+	if err == nil {
+		return false
+	}
+	for _, t := range FindAll[interface{ Temporary() bool }](err) {
+		if t.Temporary() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RetryPolicy controls how Retry spaces out attempts.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of calls, including the first one.
+	// Values below 1 are treated as 1.
+	MaxAttempts int
+	// InitialBackoff is the wait before the second attempt.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between attempts, if positive.
+	MaxBackoff time.Duration
+	// Multiplier grows the wait after each attempt.
+	// Values below 1 are treated as 1.
+	Multiplier float64
+}
+
+// DefaultRetryPolicy is a reasonable policy for calls to remote services.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     5 * time.Second,
+	Multiplier:     2,
+}
+
+// Retry calls fn until it succeeds, returns an error that is not
+// IsRetryable, policy.MaxAttempts is reached, or ctx is done.
+//
+// The final error is wrapped with Fields recording "attempts" and
+// "max_attempts". If ctx ended the retries, the error is also marked
+// with ctx.Err(), so Is(err, context.Canceled) works as expected.
+func Retry(ctx context.Context, policy RetryPolicy, fn func(ctx context.Context) error) error {
+	maxAttempts := policy.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	multiplier := policy.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	backoff := policy.InitialBackoff
+
+	var err error
+	attempt := 0
+	for attempt < maxAttempts {
+		attempt++
+		if err = fn(ctx); err == nil {
+			return nil
+		}
+		if !IsRetryable(err) || attempt == maxAttempts {
+			break
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			err = Mark(err, ctx.Err())
+			return WrapWithFieldsAndDepth(err, retryFields(attempt, maxAttempts), 1)
+		case <-timer.C:
+		}
+
+		backoff = time.Duration(float64(backoff) * multiplier)
+		if policy.MaxBackoff > 0 && backoff > policy.MaxBackoff {
+			backoff = policy.MaxBackoff
+		}
+	}
+
+	return WrapWithFieldsAndDepth(err, retryFields(attempt, maxAttempts), 1)
+}
+
+func retryFields(attempt, maxAttempts int) Fields {
+	return Fields{"attempts": attempt, "max_attempts": maxAttempts}
+}
+
+type withRetryable struct {
+	cause     error
+	retryable bool
+}
+
+// compiler enforced interface conformance checks
+var (
+	_ error          = (*withRetryable)(nil)
+	_ fmt.Formatter  = (*withRetryable)(nil)
+	_ fmt.GoStringer = (*withRetryable)(nil)
+	_ Unwrapper      = (*withRetryable)(nil)
+)
+
+func (w *withRetryable) Error() string   { return w.cause.Error() }
+func (w *withRetryable) Cause() error    { return w.cause }
+func (w *withRetryable) Unwrap() error   { return w.cause }
+func (w *withRetryable) Retryable() bool { return w.retryable }
+
+// Format implements the fmt.Formatter interface.
+func (w *withRetryable) Format(st fmt.State, verb rune) {
+	formatError(st, verb, w)
+}
+
+// GoString implements the fmt.GoStringer interface, for %#v.
+func (w *withRetryable) GoString() string {
+	return fmt.Sprintf("&errors.withRetryable{cause:%#v, retryable:%t}", w.cause, w.retryable)
+}
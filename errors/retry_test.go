@@ -0,0 +1,166 @@
+package errors_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/StevenACoffman/simplerr/errors"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return false }
+
+// opError reports neither a timeout nor a temporary failure itself.
+type opError struct{ cause error }
+
+func (e opError) Error() string { return "op: " + e.cause.Error() }
+func (e opError) Unwrap() error { return e.cause }
+func (opError) Timeout() bool   { return false }
+func (opError) Temporary() bool { return false }
+
+var errThrottled = errors.Sentinel("throttled")
+
+func init() {
+	errors.RegisterRetryClassifier(func(err error) (retryable, ok bool) {
+		if errors.Is(err, errThrottled) {
+			return true, true
+		}
+		return false, false
+	})
+}
+
+func TestClassification(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		retryable bool
+		timeout   bool
+		temporary bool
+	}{
+		{name: "nil"},
+		{name: "plain", err: errors.New("boom")},
+		{
+			name:      "marked",
+			err:       errors.MarkRetryable(errors.New("boom")),
+			retryable: true,
+		},
+		{
+			name:    "marked non-retryable timeout",
+			err:     errors.MarkNonRetryable(errors.WithStack(timeoutError{})),
+			timeout: true,
+		},
+		{
+			name:      "outermost marker wins",
+			err:       errors.MarkRetryable(errors.MarkNonRetryable(io.EOF)),
+			retryable: true,
+		},
+		{
+			name:      "deadline exceeded",
+			err:       errors.Wrap(context.DeadlineExceeded, "calling upstream"),
+			retryable: true,
+			timeout:   true,
+			temporary: true,
+		},
+		{
+			name:      "timeout interface",
+			err:       fmt.Errorf("read: %w", timeoutError{}),
+			retryable: true,
+			timeout:   true,
+		},
+		{
+			name:      "deeper timeout",
+			err:       opError{cause: timeoutError{}},
+			retryable: true,
+			timeout:   true,
+		},
+		{
+			name:      "deeper temporary",
+			err:       errors.WithStack(opError{cause: syscall.EINTR}),
+			retryable: true,
+			temporary: true,
+		},
+		{
+			name:      "syscall",
+			err:       errors.WrapWithFields(syscall.EINTR, errors.Fields{"peer": "db"}),
+			retryable: true,
+			temporary: true,
+		},
+		{
+			name:      "classifier",
+			err:       errors.Wrap(errThrottled, "quota"),
+			retryable: true,
+		},
+		{name: "canceled", err: errors.WithStack(context.Canceled)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := errors.IsRetryable(tt.err); actual != tt.retryable {
+				t.Errorf("IsRetryable: expected %v but got %v", tt.retryable, actual)
+			}
+			if actual := errors.IsTimeout(tt.err); actual != tt.timeout {
+				t.Errorf("IsTimeout: expected %v but got %v", tt.timeout, actual)
+			}
+			if actual := errors.IsTemporary(tt.err); actual != tt.temporary {
+				t.Errorf("IsTemporary: expected %v but got %v", tt.temporary, actual)
+			}
+		})
+	}
+}
+
+func TestRetry(t *testing.T) {
+	policy := errors.RetryPolicy{MaxAttempts: 4, InitialBackoff: time.Microsecond}
+
+	calls := 0
+	err := errors.Retry(context.Background(), policy, func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return errors.MarkRetryable(errors.New("flaky"))
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Fatalf("expected success after 3 calls, got %v after %d", err, calls)
+	}
+
+	calls = 0
+	err = errors.Retry(context.Background(), policy, func(context.Context) error {
+		calls++
+		return errors.MarkRetryable(io.ErrUnexpectedEOF)
+	})
+	if !errors.Is(err, io.ErrUnexpectedEOF) || calls != 4 {
+		t.Fatalf("expected to give up after 4 calls, got %v after %d", err, calls)
+	}
+	fields := errors.GetFields(err)
+	if fields["attempts"] != 4 || fields["max_attempts"] != 4 {
+		t.Fatalf("expected attempt counts in fields, got %v", fields)
+	}
+
+	calls = 0
+	err = errors.Retry(context.Background(), policy, func(context.Context) error {
+		calls++
+		return io.EOF
+	})
+	if !errors.Is(err, io.EOF) || calls != 1 {
+		t.Fatalf("expected no retries of a permanent error, got %v after %d", err, calls)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	policy.InitialBackoff = time.Hour
+	err = errors.Retry(ctx, policy, func(context.Context) error {
+		return errors.MarkRetryable(io.ErrUnexpectedEOF)
+	})
+	if !errors.Is(err, context.Canceled) || !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected canceled error, got %v", err)
+	}
+	if fields := errors.GetFields(err); fields["attempts"] != 1 {
+		t.Fatalf("expected a single attempt, got %v", fields)
+	}
+}
@@ -0,0 +1,43 @@
+package errors
+
+import (
+	"fmt"
+)
+
+// Sentinel returns an error that formats as the given text, for
+// package-level sentinel errors:
+//
+//	var ErrNotFound = errors.Sentinel("not found")
+//
+// Unlike New, it captures no stack: a stack captured when the package is
+// initialized says nothing about where the error is returned. Return it
+// with WithStack or Wrap to record the stack of the return site; errors.Is
+// still matches it. Sentinel errors are immutable and compared by
+// identity, so each call returns a distinct error even if the text is
+// identical.
+func Sentinel(text string) error {
+	return &sentinel{msg: text}
+}
+
+type sentinel struct {
+	msg string
+}
+
+// compiler enforced interface conformance checks
+var (
+	_ error          = (*sentinel)(nil)
+	_ fmt.Formatter  = (*sentinel)(nil)
+	_ fmt.GoStringer = (*sentinel)(nil)
+)
+
+func (s *sentinel) Error() string { return s.msg }
+
+// Format implements the fmt.Formatter interface.
+func (s *sentinel) Format(st fmt.State, verb rune) {
+	formatError(st, verb, s)
+}
+
+// GoString implements the fmt.GoStringer interface, for %#v.
+func (s *sentinel) GoString() string {
+	return fmt.Sprintf("&errors.sentinel{msg:%q}", s.msg)
+}
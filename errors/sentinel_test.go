@@ -0,0 +1,47 @@
+package errors_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/StevenACoffman/simplerr/errors"
+)
+
+var errGone = errors.Sentinel("gone")
+
+func returnGone() error {
+	return errors.WithStack(errGone)
+}
+
+func TestSentinel(t *testing.T) {
+	if errors.GetStack(errGone) != nil {
+		t.Fatal("expected a sentinel to have no stack")
+	}
+	if errors.Sentinel("gone") == errGone { //errlint:ignore distinct values
+		t.Fatal("expected sentinels to be distinct")
+	}
+	if actual := fmt.Sprintf("%+v", errGone); actual != "(1) gone\nError types: (1) *errors.sentinel" {
+		t.Fatalf("unexpected verbose output:\n%s", actual)
+	}
+
+	// NotFound is a sentinel created with New, with the stack of the
+	// package initializer.
+	for err, target := range map[error]error{
+		returnGone():                     errGone,
+		errors.Wrap(errGone, "fetching"): errGone,
+		errors.WithStack(NotFound):       NotFound,
+	} {
+		if !errors.Is(err, target) {
+			t.Fatalf("expected %v to be %v", err, target)
+		}
+		report := errors.NewReport(err)
+		frames := report.Layers[0].Frames
+		if len(frames) == 0 || report.Layers[0].ElidedFrames {
+			t.Fatalf("expected a whole stack, got %v", frames)
+		}
+		if !strings.Contains(frames[0].Function, "returnGone") && !strings.Contains(frames[0].Function, "TestSentinel") {
+			t.Fatalf("expected the stack of the return site, got %v", frames)
+		}
+	}
+}
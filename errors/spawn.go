@@ -0,0 +1,66 @@
+package errors
+
+import (
+	"fmt"
+)
+
+// Go runs fn in a new goroutine, and returns a channel that receives its
+// result and is then closed. The stack of the caller of Go is captured
+// before the goroutine starts, and attached to the error returned by fn
+// with WithSpawnSite, so that the error leads back to the code that
+// launched the goroutine and not only to runtime.goexit:
+//
+//	errc := errors.Go(func() error { return fetch(url) })
+//	...
+//	if err := <-errc; err != nil {
+//		fmt.Printf("%+v", err) // ... -- Spawned from: main.crawl ...
+//	}
+func Go(fn func() error) <-chan error {
+	site := Callers(2)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(errc)
+		errc <- WithSpawnSite(fn(), site)
+	}()
+
+	return errc
+}
+
+// WithSpawnSite annotates err with site, the stack of the code that
+// spawned the goroutine err was produced in. It is rendered as a
+// "-- Spawned from:" section by %+v. If err or site is nil, err is
+// returned as is.
+func WithSpawnSite(err error, site *Stack) error {
+	if err == nil || site == nil {
+		return err
+	}
+
+	return &withSpawnSite{cause: err, site: site}
+}
+
+type withSpawnSite struct {
+	cause error
+	site  *Stack
+}
+
+// compiler enforced interface conformance checks
+var (
+	_ error          = (*withSpawnSite)(nil)
+	_ fmt.Formatter  = (*withSpawnSite)(nil)
+	_ fmt.GoStringer = (*withSpawnSite)(nil)
+	_ Unwrapper      = (*withSpawnSite)(nil)
+)
+
+func (w *withSpawnSite) Error() string { return w.cause.Error() }
+func (w *withSpawnSite) Cause() error  { return w.cause }
+func (w *withSpawnSite) Unwrap() error { return w.cause }
+
+// Format implements the fmt.Formatter interface.
+func (w *withSpawnSite) Format(st fmt.State, verb rune) {
+	formatError(st, verb, w)
+}
+
+// GoString implements the fmt.GoStringer interface, for %#v.
+func (w *withSpawnSite) GoString() string {
+	return fmt.Sprintf("&errors.withSpawnSite{cause:%#v, site:%#v}", w.cause, w.site)
+}
@@ -0,0 +1,48 @@
+package errors_test
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/StevenACoffman/simplerr/errors"
+)
+
+func spawnFailing() <-chan error {
+	return errors.Go(func() error {
+		return errors.Wrap(io.EOF, "fetching")
+	})
+}
+
+func TestGo(t *testing.T) {
+	errc := spawnFailing()
+	err := <-errc
+	if _, open := <-errc; open {
+		t.Fatal("expected the channel to be closed")
+	}
+	if !errors.Is(err, io.EOF) || err.Error() != "fetching: EOF" {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	report := errors.NewReport(err)
+	spawned := report.Layers[0].SpawnedFrom
+	if len(spawned) < 2 || !strings.HasSuffix(spawned[0].Function, "errors_test.spawnFailing") ||
+		!strings.HasSuffix(spawned[1].Function, "errors_test.TestGo") {
+		t.Fatalf("unexpected spawn site %v", spawned)
+	}
+	verbose := fmt.Sprintf("%+v", err)
+	if !strings.Contains(verbose, "\n  -- Spawned from:github.com/StevenACoffman/simplerr/errors_test.spawnFailing\n  | \t") {
+		t.Fatalf("expected the spawn site in:\n%s", verbose)
+	}
+	if compact := errors.FormatWith(err, errors.CompactFormatter{}); !strings.Contains(compact, " (spawned from github.com/StevenACoffman/simplerr/errors_test.spawnFailing ") {
+		t.Fatalf("expected the spawn site in:\n%s", compact)
+	}
+
+	if err := <-errors.Go(func() error { return nil }); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := errors.WithSpawnSite(io.EOF, nil); err != io.EOF { //errlint:ignore err as is
+		t.Fatalf("expected err as is without a site, got %#v", err)
+	}
+}
@@ -3,16 +3,21 @@ package errors
 import (
 	"bytes"
 	"fmt"
+	"io"
+	"path"
 	"runtime"
 	"strconv"
+	"strings"
 )
 
 // Callers mirrors the code in github.com/pkg/errors,
-// but makes the skip depth customizable.
+// but makes the skip depth customizable. It honors the capture policy set
+// by SetCapturePolicy, and returns nil when the policy captures nothing.
+// Every method of *Stack accepts a nil Stack, which has no frames.
 func Callers(skip int) *Stack {
-	var st Stack = captureStacktrace(skip)
+	st, _ := capture(skip+1, GetCapturePolicy())
 
-	return &st
+	return st
 }
 
 func captureStacktrace(skip int) []uintptr {
@@ -31,7 +36,7 @@ func captureStacktrace(skip int) []uintptr {
 	}
 	pcs = pcs[:numFrames]
 
-	newPCs := make([]uintptr, numFrames)
+	newPCs := make([]uintptr, 0, numFrames)
 	for i := range pcs[0:numFrames] {
 		newPCs = append(newPCs, pcs[i])
 	}
@@ -44,20 +49,121 @@ type Stack []uintptr
 
 // compiler enforced interface compliance test
 var (
-	_ fmt.Formatter = (*withFields)(nil)
+	_ fmt.Formatter  = (*Stack)(nil)
+	_ fmt.GoStringer = (*Stack)(nil)
 )
 
 // Format mirrors the code in github.com/pkg/errors.
 // https://github.com/pkg/errors/blob/master/stack.go#L142
+//
+//	%s    the source file of each frame, as [file file]
+//	%v    the source file and line of each frame, as [file:line file:line]
+//	%+v   the function, file and line of each frame, one per line
+//	%#v   a Go-syntax representation of the program counters
 func (s *Stack) Format(st fmt.State, verb rune) {
-	if verb == 'v' {
-		_, _ = fmt.Fprintf(st, "\n%+v", s.StackTrace().String())
+	if s == nil {
+		_, _ = io.WriteString(st, "<nil>")
+		return
 	}
+	switch verb {
+	case 'v':
+		switch {
+		case st.Flag('+'):
+			_, _ = fmt.Fprintf(st, "\n%+v", s.StackTrace().String())
+		case st.Flag('#'):
+			_, _ = io.WriteString(st, s.GoString())
+		default:
+			s.formatFiles(st, true)
+		}
+	case 's':
+		s.formatFiles(st, false)
+	}
+}
+
+// formatFiles writes the base name of the source file of each frame, and
+// optionally its line, as a bracketed list.
+func (s *Stack) formatFiles(st fmt.State, withLine bool) {
+	_, _ = io.WriteString(st, "[")
+	// As in FormatStack, the final runtime frame is dropped.
+	frames := s.StackTrace()
+	i := 0
+	for frame, more := frames.Next(); more; frame, more = frames.Next() {
+		if i > 0 {
+			_, _ = io.WriteString(st, " ")
+		}
+		_, _ = io.WriteString(st, path.Base(frame.File))
+		if withLine {
+			_, _ = io.WriteString(st, ":"+strconv.Itoa(frame.Line))
+		}
+		i++
+	}
+	_, _ = io.WriteString(st, "]")
+}
+
+// isWhole reports whether s goes up to the root of its goroutine, that is
+// whether its last frame is runtime.goexit or runtime.main.
+func (s *Stack) isWhole() bool {
+	if s == nil || len(*s) == 0 {
+		return false
+	}
+	name := funcName((*s)[len(*s)-1])
+
+	return name == "runtime.goexit" || name == "runtime.main"
+}
+
+// capturedAtInit reports whether s was captured while initializing a
+// package, such as the stack of a sentinel error created with New.
+func (s *Stack) capturedAtInit() bool {
+	if s == nil {
+		return false
+	}
+	// The initializers are called by runtime.main, a few frames from the
+	// end of the stack.
+	for i := len(*s) - 1; i >= 0 && i >= len(*s)-4; i-- {
+		if strings.HasPrefix(funcName((*s)[i]), "runtime.doInit") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// funcName returns the name of the function of a program counter returned
+// by runtime.Callers, or "".
+func funcName(pc uintptr) string {
+	fn := runtime.FuncForPC(pc - 1)
+	if fn == nil {
+		return ""
+	}
+
+	return fn.Name()
 }
 
-// StackTrace mirrors the code in github.com/pkg/errors.
+// GoString implements the fmt.GoStringer interface, for %#v.
+func (s *Stack) GoString() string {
+	if s == nil {
+		return "(*errors.Stack)(nil)"
+	}
+	var sb strings.Builder
+	_, _ = sb.WriteString("&errors.Stack{")
+	for i, pc := range *s {
+		if i > 0 {
+			_, _ = sb.WriteString(", ")
+		}
+		_, _ = sb.WriteString("0x" + strconv.FormatUint(uint64(pc), 16))
+	}
+	_, _ = sb.WriteString("}")
+
+	return sb.String()
+}
+
+// StackTrace mirrors the code in github.com/pkg/errors. The StackTrace of
+// a nil Stack is empty.
 func (s *Stack) StackTrace() *StackTrace {
-	pcs := []uintptr(*s)
+	var pcs []uintptr
+	if s != nil {
+		pcs = *s
+	}
 
 	return (*StackTrace)(runtime.CallersFrames(pcs))
 }
@@ -120,7 +226,7 @@ func (sf *stackTraceFormatter) FormatFrame(frame runtime.Frame) {
 
 // ElideSharedStackSuffix removes the suffix of newStack that's already
 // present in prevStack. The function returns true if some entries
-// were elided.
+// were elided. At least one entry is always kept.
 // type StackTrace []Frame -> type StackTrace runtime.Frames
 // callers []uintptr
 func ElideSharedStackSuffix(prevStack, newStack *Stack) (*Stack, bool) {
@@ -137,17 +243,19 @@ func ElideSharedStackSuffix(prevStack, newStack *Stack) (*Stack, bool) {
 		return newStack, false
 	}
 
-	// Skip over the common suffix.
-	var i, j int
-	for i, j = len(newSt)-1, len(prevSt)-1; i > 0 && j > 0; i, j = i-1, j-1 {
-		if (newSt)[i] != (prevSt)[j] {
+	// Skip over the common suffix. Afterwards, newSt[i] is the innermost
+	// entry that is not shared, if any.
+	i, j := len(newSt)-1, len(prevSt)-1
+	for ; i >= 0 && j >= 0; i, j = i-1, j-1 {
+		if newSt[i] != prevSt[j] {
 			break
 		}
 	}
-	if i == 0 {
+	keep := i + 1
+	if keep == 0 {
 		// Keep at least one entry.
-		i = 1
+		keep = 1
 	}
-	elidedStack := newSt[:i]
-	return &(elidedStack), i < len(newSt)-1
+	elidedStack := newSt[:keep]
+	return &elidedStack, keep < len(newSt)
 }
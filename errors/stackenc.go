@@ -0,0 +1,100 @@
+package errors
+
+import (
+	reflectlite "reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/StevenACoffman/simplerr/errors/internal/buildid"
+)
+
+// encodedStackPrefix starts the compact encoding of a Stack, and versions
+// its format.
+const encodedStackPrefix = "stk1:"
+
+// EncodedStack is the compact form of a Stack: the build ID of the binary
+// that captured it, and its program counters as offsets from the entry of
+// the Callers function. Unlike raw program counters, offsets do not depend
+// on where the binary was loaded, so a symbolizer can resolve them offline
+// against the text segment of the same binary (see the errsym package).
+type EncodedStack struct {
+	BuildID string
+	Offsets []int64
+}
+
+// Encode returns the compact encoding of s, a single token of the form
+//
+//	stk1:<build ID>:<offset>,<offset>,...
+//
+// with hexadecimal offsets. It is cheap enough for hot paths: no symbol is
+// resolved. The build ID is read from the running binary once, and is
+// empty on platforms other than ELF ones.
+func (s *Stack) Encode() string {
+	if s == nil {
+		return encodedStackPrefix + buildid.Self() + ":"
+	}
+	anchor := anchorPC()
+	var b strings.Builder
+	b.Grow(len(encodedStackPrefix) + 64 + len(*s)*6)
+	b.WriteString(encodedStackPrefix)
+	b.WriteString(buildid.Self())
+	b.WriteByte(':')
+	for i, pc := range *s {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatInt(int64(pc)-int64(anchor), 16))
+	}
+
+	return b.String()
+}
+
+// ErrMalformedStack is returned by DecodeStack for a string that is not
+// the output of Stack.Encode.
+var ErrMalformedStack = Sentinel("decoding stack: malformed encoding")
+
+// DecodeStack parses the output of Stack.Encode.
+func DecodeStack(s string) (*EncodedStack, error) {
+	if !strings.HasPrefix(s, encodedStackPrefix) {
+		return nil, ErrMalformedStack
+	}
+	id, offsets, ok := strings.Cut(strings.TrimPrefix(s, encodedStackPrefix), ":")
+	if !ok {
+		return nil, ErrMalformedStack
+	}
+	enc := &EncodedStack{BuildID: id}
+	if offsets == "" {
+		return enc, nil
+	}
+	for _, field := range strings.Split(offsets, ",") {
+		off, err := strconv.ParseInt(field, 16, 64)
+		if err != nil {
+			return nil, With(err, ErrMalformedStack)
+		}
+		enc.Offsets = append(enc.Offsets, off)
+	}
+
+	return enc, nil
+}
+
+// GetStack returns the stack trace of the outermost error in err's chain
+// that has one, or nil.
+func GetStack(err error) *Stack {
+	return getLastStack(err)
+}
+
+var (
+	anchorOnce sync.Once
+	anchor     uintptr
+)
+
+// anchorPC returns the entry of Callers in the running binary, which the
+// offsets of an EncodedStack are relative to. errsym looks it up by name.
+func anchorPC() uintptr {
+	anchorOnce.Do(func() {
+		anchor = reflectlite.ValueOf(Callers).Pointer()
+	})
+
+	return anchor
+}
@@ -42,7 +42,8 @@ func Unwrap(err error) error { return UnwrapOnce(err) }
 // matches the type to which target points.
 //
 // Note: this implementation differs from that of xerrors as follows:
-// - it also supports recursing through causes with Cause().
+// - it also supports recursing through causes with Cause(), as set by
+// SetTraversalPolicy.
 // - if it detects an API use error, its panic object is a valid error.
 
 // As finds the first error in err's chain that matches the type to which
@@ -56,7 +57,8 @@ func Unwrap(err error) error { return UnwrapOnce(err) }
 // matches the type to which target points.
 //
 // Note: this implementation differs from that of xerrors as follows:
-// - it also supports recursing through causes with Cause().
+// - it also supports recursing through causes with Cause(), as set by
+// SetTraversalPolicy.
 // - if it detects an API use error, its panic object is a valid error.
 func As(err error, target any) bool {
 	if target == nil {
@@ -86,6 +88,14 @@ func As(err error, target any) bool {
 		if x, ok := c.(interface{ As(any) bool }); ok && x.As(target) {
 			return true
 		}
+		if multi, ok := c.(interface{ Unwrap() []error }); ok && UnwrapOnce(c) == nil {
+			for _, cause := range multi.Unwrap() {
+				if cause != nil && As(cause, target) {
+					return true
+				}
+			}
+			return false
+		}
 	}
 
 	return false
@@ -125,10 +135,14 @@ var errorType = reflectlite.TypeOf((*error)(nil)).Elem()
 // Note: if any of the error types has been migrated from a previous
 // package location or a different type, ensure that
 // RegisterTypeMigration() was called prior to Is().
+//
+// If reference is a keyed sentinel (see KeyedSentinel and SentinelWithID),
+// an error that has the same key, as returned by ErrorKey, also matches.
 func Is(err, reference error) bool {
 	if reference == nil {
 		return err == nil
 	}
+	ref, keyed := reference.(keyer)
 
 	// Direct reference comparison is the fastest, and most
 	// likely to be true, so do this first.
@@ -141,6 +155,21 @@ func Is(err, reference error) bool {
 		if tryDelegateToIsMethod(c, reference) {
 			return true
 		}
+		// Errors that are copies of a keyed sentinel, such as the ones
+		// loaded from a plugin or decoded from JSON, match by key.
+		if keyed && hasKey(c, ref.ErrorKey()) {
+			return true
+		}
+		// As in the Go standard library, every branch of a multi-error
+		// is searched.
+		if multi, ok := c.(interface{ Unwrap() []error }); ok && UnwrapOnce(c) == nil {
+			for _, cause := range multi.Unwrap() {
+				if cause != nil && Is(cause, reference) {
+					return true
+				}
+			}
+			return false
+		}
 	}
 
 	if err == nil {
@@ -0,0 +1,73 @@
+package errors
+
+import "runtime"
+
+// Trace records that err passed through the caller, for a return-path
+// trace in %+v, similar to Zig's error return traces. Call it at every
+// return site:
+//
+//	if err := load(); err != nil {
+//		return errors.Trace(err)
+//	}
+//
+// If err has no stack yet, as with sentinel errors, or only the one of a
+// package initializer, Trace captures one like WithStack. Otherwise, it records only the program counter of the
+// caller, as a hop of the return trace, which costs a fraction of a stack
+// capture (see BenchmarkReturnTrace). The hop is held by a layer of its
+// own, which %+v does not list: its hop is shown in the return trace.
+func Trace(err error) error {
+	if err == nil {
+		return nil
+	}
+	if !hasStack(err) {
+		return WithStackDepth(err, 1)
+	}
+	pc := callerPC(1)
+	if pc == 0 {
+		return err
+	}
+
+	return forward(&withStack{cause: err, returnTrace: []uintptr{pc}}, err)
+}
+
+// hasStack reports whether err has a stack, other than one captured by a
+// package initializer, which says nothing about where err was returned.
+func hasStack(err error) bool {
+	st := getLastStack(err)
+	return st != nil && !st.capturedAtInit()
+}
+
+// isHop reports whether err is a layer added by Trace, which holds a hop
+// of the return trace and no stack.
+func isHop(err error) bool {
+	w, ok := err.(*withStack)
+	return ok && w.Stack == nil && len(w.returnTrace) > 0
+}
+
+// callerPC returns the program counter of a caller, or 0 if the capture
+// policy captures nothing. skip=0 identifies the caller of callerPC.
+func callerPC(skip int) uintptr {
+	if GetCapturePolicy().Mode == CaptureDisabled {
+		return 0
+	}
+	var pc [1]uintptr
+	// +2 to skip runtime.Callers and callerPC.
+	if runtime.Callers(skip+2, pc[:]) == 0 {
+		return 0
+	}
+
+	return pc[0]
+}
+
+// hopFrames returns the frame of each program counter of a return trace.
+func hopFrames(pcs []uintptr) []runtime.Frame {
+	frames := make([]runtime.Frame, 0, len(pcs))
+	for _, pc := range pcs {
+		// A program counter in an inlined call stands for several frames,
+		// of which the innermost is the one of the hop.
+		frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		frames = append(frames, frame)
+	}
+
+	return frames
+}
@@ -0,0 +1,177 @@
+package errors_test
+
+import (
+	stderrs "errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/StevenACoffman/simplerr/errors"
+)
+
+func traceLoad() error {
+	return errors.Trace(errGone)
+}
+
+func traceService() error {
+	if err := traceLoad(); err != nil {
+		return errors.Wrap(err, "service")
+	}
+	return nil
+}
+
+func traceHandler() error {
+	if err := traceService(); err != nil {
+		return errors.Trace(err)
+	}
+	return nil
+}
+
+// hops returns the last element of the function names of frames.
+func hops(report *errors.Report) string {
+	names := make([]string, 0, len(report.ReturnTrace))
+	for _, frame := range report.ReturnTrace {
+		names = append(names, frame.Function[strings.LastIndex(frame.Function, ".")+1:])
+	}
+	return strings.Join(names, " ")
+}
+
+func TestTrace(t *testing.T) {
+	err := errors.Trace(traceHandler())
+	if !errors.Is(err, errGone) {
+		t.Fatalf("expected %v to be %v", err, errGone)
+	}
+	if err.Error() != "service: "+errGone.Error() {
+		t.Fatalf("unexpected message %q", err.Error())
+	}
+
+	report := errors.NewReport(err)
+	// The first Trace captures a stack, Wrap and the next Traces record
+	// only a frame each, in layers that are not listed.
+	if actual := hops(report); actual != "traceService traceHandler TestTrace" {
+		t.Fatalf("unexpected return trace %s", actual)
+	}
+	types := make([]string, 0, len(report.Layers))
+	for _, layer := range report.Layers {
+		types = append(types, layer.Type)
+	}
+	if actual := strings.Join(types, " "); actual != "*errors.wrapper *errors.withStack *errors.sentinel" {
+		t.Fatalf("unexpected layers %s", actual)
+	}
+	if inner := report.Layers[1]; inner.ElidedFrames ||
+		!strings.HasSuffix(inner.Frames[0].Function, "errors_test.traceLoad") {
+		t.Errorf("expected the first Trace to capture a whole stack, got %v", inner.Frames)
+	}
+	if !strings.Contains(fmt.Sprintf("%+v", err), "\nReturn trace:\n  | github.com/StevenACoffman/simplerr/errors_test.traceService\n") {
+		t.Errorf("expected a return trace section in:\n%+v", err)
+	}
+
+	// Hops are not shared with the other paths of an error.
+	base := traceLoad()
+	_ = errors.Trace(base)
+	if actual := hops(errors.NewReport(errors.Trace(base))); actual != "TestTrace" {
+		t.Errorf("unexpected return trace %s", actual)
+	}
+
+	if errors.Trace(nil) != nil {
+		t.Fatal("expected Trace(nil) to be nil")
+	}
+}
+
+func TestTraceIs(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		err  error
+		hops int
+	}{
+		{name: "New", err: errors.New("boom"), hops: 2},
+		{name: "WithStack", err: errors.WithStack(io.EOF), hops: 2},
+		// Wrap records a hop too.
+		{name: "Wrap", err: errors.Wrap(errors.New("boom"), "ctx"), hops: 3},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			traced := errors.Trace(errors.Trace(tt.err))
+			if !errors.Is(traced, tt.err) {
+				t.Errorf("expected Is(Trace(%v), %[1]v)", tt.err)
+			}
+			if !stderrs.Is(traced, tt.err) {
+				t.Errorf("expected the Is of the standard library to find %v", tt.err)
+			}
+			if hops := errors.NewReport(traced).ReturnTrace; len(hops) != tt.hops {
+				t.Errorf("expected %d hops, got %v", tt.hops, hops)
+			}
+		})
+	}
+}
+
+func TestTraceInit(t *testing.T) {
+	// initError only has the stack of the package initializer, so a stack
+	// is captured instead of a hop.
+	for _, err := range []error{errors.Trace(initError), errors.Wrap(initError, "ctx")} {
+		frames := errors.NewReport(err).Layers[0].Frames
+		if len(frames) < 2 || !strings.HasSuffix(frames[0].Function, "errors_test.TestTraceInit") {
+			t.Errorf("expected the stack of the return site, got %v", frames)
+		}
+	}
+}
+
+func TestTraceStackless(t *testing.T) {
+	// The layers that hold only a return trace have a nil stack.
+	for _, err := range []error{
+		errors.Wrap(errors.New("boom"), "ctx"),
+		errors.Trace(fmt.Errorf("ctx: %w", errors.New("boom"))),
+	} {
+		var st interface{ StackTrace() *errors.StackTrace }
+		if !errors.As(err, &st) {
+			t.Fatalf("expected %v to have a stack trace", err)
+		}
+		if s := st.StackTrace().String(); s != "" {
+			t.Errorf("expected the outer stack trace to be empty, got %q", s)
+		}
+		if s := fmt.Sprintf("%+v", err); !strings.Contains(s, "Return trace:") {
+			t.Errorf("expected a return trace, got %q", s)
+		}
+	}
+}
+
+func TestTraceDisabled(t *testing.T) {
+	withCapturePolicy(t, errors.CapturePolicy{Mode: errors.CaptureDisabled})
+
+	err := errors.WithStackDepthPolicy(errGone, 0, errors.CapturePolicy{Mode: errors.CaptureFull})
+	if errors.Trace(err) != err { //errlint:ignore err as is
+		t.Error("expected Trace to record nothing when capture is disabled")
+	}
+}
+
+// BenchmarkReturnTrace compares recording a hop of the return trace with
+// capturing a stack, 32 calls deep.
+func BenchmarkReturnTrace(b *testing.B) {
+	err := newAtDepth(0)
+	b.Run("Trace", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = traceAtDepth(32, err)
+		}
+	})
+	b.Run("Callers", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = callersAtDepth(32)
+		}
+	})
+}
+
+func traceAtDepth(depth int, err error) error {
+	if depth == 0 {
+		return errors.Trace(err)
+	}
+	return traceAtDepth(depth-1, err)
+}
+
+func callersAtDepth(depth int) *errors.Stack {
+	if depth == 0 {
+		return errors.Callers(0)
+	}
+	return callersAtDepth(depth - 1)
+}
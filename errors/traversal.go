@@ -0,0 +1,58 @@
+package errors
+
+import "sync/atomic"
+
+// TraversalPolicy selects how UnwrapOnce finds the cause of an error, and
+// thus how every function of this package that goes down a chain does:
+// Is, As, AsType, UnwrapAll, Cause, Walk, GetFields, GetStack and the
+// %+v rendering.
+//
+// It only matters for errors whose Cause() and Unwrap() methods differ,
+// and for errors that have only one of them: the standard library and
+// golang.org/x/xerrors use only Unwrap(), github.com/pkg/errors' Cause uses
+// only Cause(). The wrappers of this package have both, returning the
+// same error.
+type TraversalPolicy int
+
+const (
+	// PreferCause uses Cause() if the error has it, and Unwrap() otherwise.
+	// This is the default.
+	PreferCause TraversalPolicy = iota
+	// PreferUnwrap uses Unwrap() if the error has it, and Cause()
+	// otherwise.
+	PreferUnwrap
+	// UnwrapOnly uses only Unwrap(), so chains are traversed exactly as by
+	// the standard library.
+	UnwrapOnly
+)
+
+var traversalPolicy atomic.Value // TraversalPolicy
+
+// SetTraversalPolicy sets the policy used by UnwrapOnce, and returns the
+// previous one.
+func SetTraversalPolicy(p TraversalPolicy) TraversalPolicy {
+	prev := GetTraversalPolicy()
+	traversalPolicy.Store(p)
+
+	return prev
+}
+
+// GetTraversalPolicy returns the policy set by SetTraversalPolicy.
+func GetTraversalPolicy() TraversalPolicy {
+	p, _ := traversalPolicy.Load().(TraversalPolicy)
+	return p
+}
+
+// UnwrapOnce returns the direct cause of err according to p, or nil.
+func (p TraversalPolicy) UnwrapOnce(err error) error {
+	causer, hasCause := err.(interface{ Cause() error })
+	unwrapper, hasUnwrap := err.(interface{ Unwrap() error })
+	switch {
+	case hasCause && (p == PreferCause || !hasUnwrap) && p != UnwrapOnly:
+		return causer.Cause()
+	case hasUnwrap:
+		return unwrapper.Unwrap()
+	}
+
+	return nil
+}
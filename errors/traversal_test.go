@@ -0,0 +1,112 @@
+package errors_test
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/StevenACoffman/simplerr/errors"
+)
+
+// divergingError has a Cause that skips ahead to the root cause, and an
+// Unwrap that returns the next error.
+type divergingError struct{ next, root error }
+
+func (e divergingError) Error() string { return "diverging: " + e.next.Error() }
+func (e divergingError) Cause() error  { return e.root }
+func (e divergingError) Unwrap() error { return e.next }
+
+// causeError has only Cause, as the errors of github.com/pkg/errors before
+// v0.9.
+type causeError struct{ cause error }
+
+func (e causeError) Error() string { return "cause: " + e.cause.Error() }
+func (e causeError) Cause() error  { return e.cause }
+
+func withTraversalPolicy(t testing.TB, p errors.TraversalPolicy) {
+	prev := errors.SetTraversalPolicy(p)
+	t.Cleanup(func() { errors.SetTraversalPolicy(prev) })
+}
+
+func TestTraversalPolicy(t *testing.T) {
+	middle := fmt.Errorf("middle: %w", io.EOF)
+	diverging := divergingError{next: middle, root: io.EOF}
+	causer := causeError{cause: io.ErrUnexpectedEOF}
+
+	tests := []struct {
+		name       string
+		policy     errors.TraversalPolicy
+		diverging  error
+		causer     error
+		isMiddle   bool
+		isCauseEnd bool
+	}{
+		{name: "PreferCause", policy: errors.PreferCause, diverging: io.EOF, causer: io.ErrUnexpectedEOF, isCauseEnd: true},
+		{name: "PreferUnwrap", policy: errors.PreferUnwrap, diverging: middle, causer: io.ErrUnexpectedEOF, isMiddle: true, isCauseEnd: true},
+		{name: "UnwrapOnly", policy: errors.UnwrapOnly, diverging: middle, causer: nil, isMiddle: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := tt.policy.UnwrapOnce(diverging); actual != tt.diverging { //errlint:ignore the layer itself
+				t.Errorf("expected %v, got %v", tt.diverging, actual)
+			}
+			if actual := tt.policy.UnwrapOnce(causer); actual != tt.causer { //errlint:ignore the layer itself
+				t.Errorf("expected %v, got %v", tt.causer, actual)
+			}
+
+			withTraversalPolicy(t, tt.policy)
+			if actual := errors.UnwrapOnce(diverging); actual != tt.diverging { //errlint:ignore the layer itself
+				t.Errorf("expected UnwrapOnce to follow the policy, got %v", actual)
+			}
+			// The wrappers of this package do not change the traversal.
+			err := errors.WrapWithFields(errors.Wrap(diverging, "wrapped"), errors.Fields{"k": 1})
+			if actual := errors.Is(err, middle); actual != tt.isMiddle {
+				t.Errorf("expected Is(middle) to be %t", tt.isMiddle)
+			}
+			if !errors.Is(err, io.EOF) {
+				t.Error("expected every policy to reach io.EOF")
+			}
+			if actual := errors.Is(errors.WithStack(causer), io.ErrUnexpectedEOF); actual != tt.isCauseEnd {
+				t.Errorf("expected Is through Cause() only to be %t", tt.isCauseEnd)
+			}
+			if actual := errors.UnwrapAll(causer); (actual == causer) == tt.isCauseEnd { //errlint:ignore the layer itself
+				t.Errorf("unexpected root cause %v", actual)
+			}
+			var found int
+			errors.Walk(err, func(e error, _ int, _ []int) bool {
+				if e == middle { //errlint:ignore the layer itself
+					found++
+				}
+				return true
+			})
+			if (found == 1) != tt.isMiddle {
+				t.Errorf("expected Walk to visit middle %t, visited it %d times", tt.isMiddle, found)
+			}
+			if fields := errors.GetFields(err); fields["k"] != 1 {
+				t.Errorf("unexpected fields %v", fields)
+			}
+			if errors.GetStack(err) == nil {
+				t.Error("expected a stack")
+			}
+		})
+	}
+}
+
+type multiError []error
+
+func (m multiError) Error() string   { return fmt.Sprint([]error(m)) }
+func (m multiError) Unwrap() []error { return m }
+
+func TestIsAsMultiError(t *testing.T) {
+	err := errors.WithStack(multiError{io.EOF, errors.WithStack(myError("leaf"))})
+	if !errors.Is(err, io.EOF) {
+		t.Error("expected the first branch to be searched")
+	}
+	var target myError
+	if !errors.As(err, &target) || target != "leaf" {
+		t.Errorf("expected the second branch to be searched, got %q", target)
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Error("expected no match")
+	}
+}
@@ -12,16 +12,9 @@ package errors
 //
 // It supports both errors implementing causer (`Cause()` method, from
 // github.com/pkg/errors) and `Wrapper` (`Unwrap()` method, from the
-// Go 2 error proposal).
+// Go 2 error proposal), in the order set by SetTraversalPolicy.
 func UnwrapOnce(err error) (cause error) {
-	switch e := err.(type) {
-	case interface{ Cause() error }:
-		return e.Cause()
-	case interface{ Unwrap() error }:
-		return e.Unwrap()
-	}
-
-	return nil
+	return GetTraversalPolicy().UnwrapOnce(err)
 }
 
 // UnwrapAll accesses the root cause object of the error.
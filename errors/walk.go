@@ -0,0 +1,91 @@
+package errors
+
+// Walk visits err and every error reachable from it, depth first and
+// outermost first, calling fn for each of them. It stops as soon as fn
+// returns false.
+//
+// depth is 0 for err itself and grows by one for each level of wrapping.
+// path holds, for each level, the index of the child that was followed to
+// reach e: the only cause of an error has index 0, the front and back of
+// an error built with With have indices 0 and 1, and the errors returned
+// by an Unwrap() []error method are numbered in order. path is reused
+// between calls, so fn must copy it to retain it.
+//
+// Causes are found with UnwrapOnce, according to the traversal policy,
+// and the errors of an Unwrap() []error method are visited when there is
+// none. Errors built with With are visited as a tree: the whole chain of
+// the front error, then the back error, each exactly once. A wrapper that
+// embeds Forwarding is visited as the wrapper it extends.
+func Walk(err error, fn func(e error, depth int, path []int) bool) {
+	if err == nil {
+		return
+	}
+	walk(err, 0, make([]int, 0, 8), fn)
+}
+
+func walk(err error, depth int, path []int, fn func(error, int, []int) bool) bool {
+	// The forwarding of a wrapper adds no layer, as in the chain of err.
+	err = unforward(err)
+	if !fn(err, depth, path) {
+		return false
+	}
+
+	if e, ok := err.(*wrapper); ok {
+		return walk(e.front, depth+1, append(path, 0), fn) &&
+			walk(e.back, depth+1, append(path, 1), fn)
+	}
+	if cause := UnwrapOnce(err); cause != nil {
+		return walk(cause, depth+1, append(path, 0), fn)
+	}
+	if e, ok := err.(interface{ Unwrap() []error }); ok {
+		for i, cause := range e.Unwrap() {
+			if cause != nil && !walk(cause, depth+1, append(path, i), fn) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// Find returns the first error reachable from err, in the order used by
+// Walk, that has type T. T may be an interface type.
+func Find[T any](err error) (T, bool) {
+	var found T
+	var ok bool
+	Walk(err, func(e error, _ int, _ []int) bool {
+		found, ok = e.(T)
+		return !ok
+	})
+
+	return found, ok
+}
+
+// FindAll returns every error reachable from err, in the order used by
+// Walk, that has type T. T may be an interface type.
+func FindAll[T any](err error) []T {
+	var found []T
+	Walk(err, func(e error, _ int, _ []int) bool {
+		if t, ok := e.(T); ok {
+			found = append(found, t)
+		}
+		return true
+	})
+
+	return found
+}
+
+// Depth returns the number of errors on the longest path from err to a
+// leaf error, as visited by Walk. It returns 0 for a nil error and 1 for
+// an error that wraps nothing.
+func Depth(err error) int {
+	maxDepth := 0
+	Walk(err, func(_ error, depth int, _ []int) bool {
+		if depth+1 > maxDepth {
+			maxDepth = depth + 1
+		}
+		return true
+	})
+
+	return maxDepth
+}
@@ -0,0 +1,74 @@
+package errors_test
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/StevenACoffman/simplerr/errors"
+)
+
+func TestWalk(t *testing.T) {
+	leaf := myError("leaf")
+	front := fmt.Errorf("front: %w", leaf)
+	back := otherError{msg: "back"}
+	with := errors.With(back, front)
+	err := fmt.Errorf("%w + %w", with, io.EOF)
+
+	type visit struct {
+		err   error
+		depth int
+		path  []int
+	}
+	var visits []visit
+	errors.Walk(err, func(e error, depth int, path []int) bool {
+		visits = append(visits, visit{err: e, depth: depth, path: append([]int{}, path...)})
+		return true
+	})
+	expected := []visit{
+		{err: err, depth: 0, path: []int{}},
+		{err: with, depth: 1, path: []int{0}},
+		{err: front, depth: 2, path: []int{0, 0}},
+		{err: leaf, depth: 3, path: []int{0, 0, 0}},
+		{err: back, depth: 2, path: []int{0, 1}},
+		{err: io.EOF, depth: 1, path: []int{1}},
+	}
+	if !reflect.DeepEqual(visits, expected) {
+		t.Fatalf("expected visits:\n%v\nbut got:\n%v", expected, visits)
+	}
+
+	var count int
+	errors.Walk(err, func(error, int, []int) bool {
+		count++
+		return count < 3
+	})
+	if count != 3 {
+		t.Fatalf("expected Walk to stop after 3 visits, got %d", count)
+	}
+
+	if depth := errors.Depth(err); depth != 4 {
+		t.Fatalf("expected depth 4 but got %d", depth)
+	}
+	if depth := errors.Depth(nil); depth != 0 {
+		t.Fatalf("expected depth 0 but got %d", depth)
+	}
+}
+
+func TestFind(t *testing.T) {
+	err := errors.WrapWithFields(
+		errors.With(otherError{msg: "back"}, myError("front")),
+		errors.Fields{"key": "value"},
+	)
+
+	if my, ok := errors.Find[myError](err); !ok || my != "front" {
+		t.Fatalf("expected to find myError, got %q, %v", my, ok)
+	}
+	if _, ok := errors.Find[*fmt.Stringer](err); ok {
+		t.Fatal("unexpectedly found a type that is not in the chain")
+	}
+	unwrappers := errors.FindAll[errors.Unwrapper](err)
+	if len(unwrappers) != 2 || unwrappers[0].(error) != err { //errlint:ignore the layer itself
+		t.Fatalf("expected the fields and With layers, got %v", unwrappers)
+	}
+}
@@ -1,7 +1,7 @@
 package errors
 
 import (
-	"errors"
+	"fmt"
 	// reflectlite is a package internal to the stdlib, but its API is the same
 	// as reflect. This renaming keeps the code below identical to that in the
 	// internals of the errors package.
@@ -35,10 +35,12 @@ type wrapper struct {
 
 // compiler enforced interface conformance checks
 var (
-	_ error     = (*wrapper)(nil)
-	_ Iser      = (*wrapper)(nil)
-	_ Aser      = (*wrapper)(nil)
-	_ Unwrapper = (*wrapper)(nil)
+	_ error          = (*wrapper)(nil)
+	_ fmt.Formatter  = (*wrapper)(nil)
+	_ fmt.GoStringer = (*wrapper)(nil)
+	_ Iser           = (*wrapper)(nil)
+	_ Aser           = (*wrapper)(nil)
+	_ Unwrapper      = (*wrapper)(nil)
 )
 
 // Is implements the interface needed for errors.Is. It checks s.front first, and
@@ -101,7 +103,7 @@ func (s *wrapper) As(target any) bool {
 // Unwrap iteratively unwraps the error wrapper in front until it runs, out of
 // wrapped errors, and then returns the back error.
 func (s *wrapper) Unwrap() error {
-	if err := errors.Unwrap(s.front); err != nil {
+	if err := UnwrapOnce(s.front); err != nil {
 		// return a new wrapper with the unwrapped err as front, so that we
 		// support unwrapping all of front and then moving on to back.
 		return &wrapper{front: err, back: s.back}
@@ -124,3 +126,13 @@ func (s *wrapper) Error() string {
 	}
 	return front + ": " + back
 }
+
+// Format implements the fmt.Formatter interface.
+func (s *wrapper) Format(st fmt.State, verb rune) {
+	formatError(st, verb, s)
+}
+
+// GoString implements the fmt.GoStringer interface, for %#v.
+func (s *wrapper) GoString() string {
+	return fmt.Sprintf("&errors.wrapper{front:%#v, back:%#v}", s.front, s.back)
+}
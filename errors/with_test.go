@@ -8,7 +8,7 @@ import (
 	"github.com/StevenACoffman/simplerr/errors"
 )
 
-var NotFound = errors.New("not found")
+var NotFound = errors.New("not found") //errlint:ignore a stack for the tests of With
 
 func TestWithIs(t *testing.T) {
 	err := errors.New("some pig")
@@ -2,7 +2,6 @@ package errors
 
 import (
 	"fmt"
-	"io"
 	reflectlite "reflect"
 	"sort"
 	"strings"
@@ -31,21 +30,19 @@ func WrapWithFieldsAndDepth(err error, fields Fields, depth int) error {
 	if fields == nil {
 		return WithStackDepth(err, depth+1)
 	}
-	st := Callers(depth + 2)
-	prevStack := getLastStack(err)
-	var hasSkippedFrames bool
-	st, hasSkippedFrames = ElideSharedStackSuffix(prevStack, st)
+	st, hasSkippedFrames := captureRelative(depth+2, GetCapturePolicy(), getWholeStack(err))
 
-	return &withFields{cause: err, hasSkippedFrames: hasSkippedFrames, Stack: st, fields: fields}
+	return forward(&withFields{cause: err, hasSkippedFrames: hasSkippedFrames, Stack: st, fields: fields}, err)
 }
 
 // compiler enforced interface conformance checks
 var (
-	_ error         = (*withFields)(nil)
-	_ fmt.Formatter = (*withFields)(nil)
-	_ Iser          = (*withFields)(nil)
-	_ Aser          = (*withFields)(nil)
-	_ Unwrapper     = (*withFields)(nil)
+	_ error          = (*withFields)(nil)
+	_ fmt.Formatter  = (*withFields)(nil)
+	_ fmt.GoStringer = (*withFields)(nil)
+	_ Iser           = (*withFields)(nil)
+	_ Aser           = (*withFields)(nil)
+	_ Unwrapper      = (*withFields)(nil)
 )
 
 // Error conforms to the error interface by returning a string representation
@@ -57,48 +54,14 @@ func (w *withFields) Unwrap() error { return w.cause }
 func (w *withFields) Cause() error  { return w.cause }
 
 // Format implements the fmt.Formatter interface.
-func (w *withFields) Format(st fmt.State, _ rune) {
-	w.formatEntries(st)
-	stackTraceString := w.StackTrace().String()
-	if stackTraceString != "" {
-		_, _ = io.WriteString(st, "\n  -- Stack trace:")
-		_, _ = io.WriteString(st, strings.ReplaceAll(
-			fmt.Sprintf("%+v", stackTraceString),
-			"\n", string(detailSep)))
-	}
+func (w *withFields) Format(st fmt.State, verb rune) {
+	formatError(st, verb, w)
 }
 
-// formatEntries reads the entries from s.entries and produces a
-// detailed rendering in s.finalBuf.
-func (w *withFields) formatEntries(st fmt.State) {
-	entries := getEntries(w)
-	if len(entries) == 0 {
-		return
-	}
-	// The first entry at the top is special. We format it as follows:
-	//
-	//   (1) <details>
-
-	_, _ = io.WriteString(st, "(1)")
-
-	printEntry(st, entries[len(entries)-1])
-
-	// All the entries that follow are printed as follows:
-	//
-	// Wraps: (N) <details>
-	//
-	for i, j := len(entries)-2, 2; i >= 0; i, j = i-1, j+1 {
-		_, _ = fmt.Fprintf(st, "\nWraps: (%d)", j)
-		entry := entries[i]
-		printEntry(st, entry)
-	}
-
-	// At the end, we link all the (N) references to the Go type of the
-	// error.
-	_, _ = io.WriteString(st, "\nError types:")
-	for i, j := len(entries)-1, 1; i >= 0; i, j = i-1, j+1 {
-		_, _ = fmt.Fprintf(st, " (%d) %T", j, entries[i])
-	}
+// GoString implements the fmt.GoStringer interface, for %#v.
+func (w *withFields) GoString() string {
+	return fmt.Sprintf("&errors.withFields{fields:%#v, cause:%#v, Stack:%#v, hasSkippedFrames:%t}",
+		w.fields, w.cause, w.Stack, w.hasSkippedFrames)
 }
 
 // getFields returns the fields of this error and any wrapped error
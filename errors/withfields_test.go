@@ -63,35 +63,3 @@ func TestWithFieldsIs(t *testing.T) {
 		t.Fatal("failed to find flagged wrapped error after wrapping")
 	}
 }
-
-func TestWithFieldsErrList(t *testing.T) {
-	one := fmt.Errorf("one")
-	two := errors.WrapWithFields(one, errors.Fields{"b": "true", "msg": "two"})
-	three := errors.WrapWithFields(two, errors.Fields{"c": "true", "msg": "three"})
-	four := errors.WrapWithFields(three, errors.Fields{"d": "true", "msg": "four"})
-	five := errors.WrapWithFields(four, errors.Fields{"e": "true", "msg": "five"})
-	six := errors.WrapWithFields(five, errors.Fields{"f": "true", "msg": "six"})
-	actual := six.Error()
-	expected := "fields:[f:true,msg:six],fields:[e:true,msg:five],fields:[d:true,msg:four],fields:[c:true,msg:three],fields:[b:true,msg:two],one"
-	if actual != expected {
-		t.Fatalf("expected %v but got %v", expected, actual)
-	}
-}
-
-func TestWithFieldsErrAllList(t *testing.T) {
-	one := fmt.Errorf("one")
-	two := errors.WrapWithFields(one, errors.Fields{"b": "true", "msg": "two"})
-	three := errors.WrapWithFields(two, errors.Fields{"c": "true", "msg": "three"})
-	actual := fmt.Sprintf("%+v", three)
-	expected := `fields:[b:true,c:true,msg:three],cause:fields:[b:true,msg:two],one
-Wraps: (1) fields:[b:true,msg:two],one
-  -- Stack trace:github.com/StevenACoffman/simplerr/errors_test.TestWithFieldsErrAllList
-  | 	github.com/StevenACoffman/simplerr/errors/withfields_test.go:83
-  | testing.tRunner
-  | 	testing/testing.go:1576
-Wraps: (2) one
-Error types: (1) *errors.withFields (2) *errors.errorString`
-	if actual != expected {
-		t.Fatalf("expected:\n %v\nbut got:\n%v", expected, actual)
-	}
-}
@@ -2,9 +2,7 @@ package errors
 
 import (
 	"fmt"
-	"io"
 	reflectlite "reflect"
-	"strings"
 )
 
 // This file mirrors the WithStack functionality from
@@ -22,30 +20,36 @@ func WithStack(err error) error {
 // given call depth. The value zero identifies the caller
 // of WithStackDepth itself.
 // See the documentation of WithStack() for more details.
+// The stack is captured according to the policy set by SetCapturePolicy.
 func WithStackDepth(err error, depth int) error {
+	return withStackDepth(err, depth+1, GetCapturePolicy())
+}
+
+func withStackDepth(err error, depth int, p CapturePolicy) error {
 	if err == nil {
 		return nil
 	}
-	st := Callers(depth + 2)
-	prevStack := getLastStack(err)
-	var hasSkippedFrames bool
-	st, hasSkippedFrames = ElideSharedStackSuffix(prevStack, st)
-	return &withStack{cause: err, hasSkippedFrames: hasSkippedFrames, Stack: st}
+	st, hasSkippedFrames := captureRelative(depth+2, p, getWholeStack(err))
+	return forward(&withStack{cause: err, hasSkippedFrames: hasSkippedFrames, Stack: st}, err)
 }
 
 type withStack struct {
 	cause error
 	*Stack
 	hasSkippedFrames bool
+	// returnTrace holds the program counter of the return site recorded
+	// by Trace or Wrap, if any.
+	returnTrace []uintptr
 }
 
 // compiler enforced interface conformance checks
 var (
-	_ error         = (*withStack)(nil)
-	_ fmt.Formatter = (*withStack)(nil)
-	_ Iser          = (*withStack)(nil)
-	_ Aser          = (*withStack)(nil)
-	_ Unwrapper     = (*withStack)(nil)
+	_ error          = (*withStack)(nil)
+	_ fmt.Formatter  = (*withStack)(nil)
+	_ fmt.GoStringer = (*withStack)(nil)
+	_ Iser           = (*withStack)(nil)
+	_ Aser           = (*withStack)(nil)
+	_ Unwrapper      = (*withStack)(nil)
 )
 
 func (w *withStack) Error() string { return w.cause.Error() }
@@ -53,9 +57,18 @@ func (w *withStack) Cause() error  { return w.cause }
 func (w *withStack) Unwrap() error { return w.cause }
 
 // Format implements the fmt.Formatter interface.
-func (w *withStack) Format(st fmt.State, _ rune) {
-	w.formatEntries(st)
-	outputStackTrace(st, false, w.StackTrace().String())
+func (w *withStack) Format(st fmt.State, verb rune) {
+	formatError(st, verb, w)
+}
+
+// GoString implements the fmt.GoStringer interface, for %#v.
+func (w *withStack) GoString() string {
+	if len(w.returnTrace) > 0 {
+		return fmt.Sprintf("&errors.withStack{cause:%#v, Stack:%#v, hasSkippedFrames:%t, returnTrace:%#v}",
+			w.cause, w.Stack, w.hasSkippedFrames, w.returnTrace)
+	}
+	return fmt.Sprintf("&errors.withStack{cause:%#v, Stack:%#v, hasSkippedFrames:%t}",
+		w.cause, w.Stack, w.hasSkippedFrames)
 }
 
 // Is implements the interface needed for errors.Is. It checks s.front first, and
@@ -115,46 +128,40 @@ func (w *withStack) As(target interface{}) bool {
 	return false
 }
 
-// formatEntries reads the entries from s.entries and produces a
-// detailed rendering in s.finalBuf.
-func (w *withStack) formatEntries(st fmt.State) {
-	entries := getEntries(w.cause)
-	if len(entries) == 0 {
-		return
-	}
-	// The first entry at the top is special. We format it as follows:
-	//
-	//   (1) <details>
-
-	_, _ = io.WriteString(st, "(1)")
-
-	printEntry(st, entries[len(entries)-1])
-
-	// All the entries that follow are printed as follows:
-	//
-	// Wraps: (N) <details>
-	//
-	for i, j := len(entries)-2, 2; i >= 0; i, j = i-1, j+1 {
-		_, _ = fmt.Fprintf(st, "\nWraps: (%d)", j)
-		entry := entries[i]
-		printEntry(st, entry)
+func getLastStack(err error) *Stack {
+	for err != nil {
+		// Layers that only hold a return trace have no stack.
+		if ws, ok := err.(*withStack); ok && ws.Stack != nil {
+			return ws.Stack
+		}
+		if wf, ok := err.(*withFields); ok && wf.Stack != nil {
+			return wf.Stack
+		}
+		err = UnwrapOnce(err)
 	}
 
-	// At the end, we link all the (N) references to the Go type of the
-	// error.
-	_, _ = io.WriteString(st, "\nError types:")
-	for i, j := len(entries)-1, 1; i >= 0; i, j = i-1, j+1 {
-		_, _ = fmt.Fprintf(st, " (%d) %T", j, entries[i])
-	}
+	return nil
 }
 
-func getLastStack(err error) *Stack {
+// getWholeStack returns the outermost stack of err's chain that goes up to
+// the root of its goroutine, to elide the frames a new stack shares with
+// it. Stacks whose shared suffix was elided, and stacks truncated by the
+// capture policy, cannot be compared with a new stack, so they are skipped.
+// So are stacks captured by package initializers, for sentinel errors
+// created with New: they share runtime.main with the stacks of the main
+// goroutine, but nothing else.
+func getWholeStack(err error) *Stack {
 	for err != nil {
-		if ws, ok := err.(*withStack); ok {
-			return ws.Stack
+		var st *Stack
+		var elided bool
+		switch e := err.(type) {
+		case *withStack:
+			st, elided = e.Stack, e.hasSkippedFrames
+		case *withFields:
+			st, elided = e.Stack, e.hasSkippedFrames
 		}
-		if wf, ok := err.(*withFields); ok {
-			return wf.Stack
+		if st != nil && !elided && st.isWhole() && !st.capturedAtInit() {
+			return st
 		}
 		err = UnwrapOnce(err)
 	}
@@ -166,40 +173,24 @@ func getLastStack(err error) *Stack {
 func getEntries(err error) []error {
 	var entries []error
 	for err != nil {
+		// Errors that extend a wrapper with forwarded methods are reported
+		// as the wrapper itself, which comes next. The layers added by
+		// Trace are reported in the return trace only.
+		if _, ok := err.(forwarding); ok || isHop(err) {
+			err = UnwrapOnce(err)
+			continue
+		}
 		// prepend because we want the stack last in, first out
 		entries = append([]error{err}, entries...)
+		// A wrapper is a single layer, which shows the message and stack of
+		// its front error, so the copies that wrapper.Unwrap returns while
+		// it unwraps the front error are not listed.
+		if w, ok := err.(*wrapper); ok {
+			err = w.back
+			continue
+		}
 		err = UnwrapOnce(err)
 	}
 
 	return entries
 }
-
-func printEntry(st fmt.State, entry error) {
-	errString := entry.Error()
-	if len(errString) > 0 {
-		if !strings.HasPrefix(errString, "\n") {
-			_, _ = io.WriteString(st, " ")
-		}
-		if len(errString) > 0 {
-			_, _ = io.WriteString(st, errString)
-		}
-	}
-	if w, ok := entry.(*withStack); ok {
-		outputStackTrace(st, w.hasSkippedFrames, w.StackTrace().String())
-	}
-	if w, ok := entry.(*withFields); ok {
-		outputStackTrace(st, w.hasSkippedFrames, w.StackTrace().String())
-	}
-}
-
-func outputStackTrace(st fmt.State, hasSkippedFrames bool, stackTraceString string) {
-	if hasSkippedFrames || strings.TrimSpace(stackTraceString) != "" {
-		_, _ = io.WriteString(st, "\n  -- Stack trace:")
-		_, _ = io.WriteString(st, strings.ReplaceAll(
-			fmt.Sprintf("%+v", stackTraceString),
-			"\n", string(detailSep)))
-	}
-	if hasSkippedFrames {
-		_, _ = fmt.Fprintf(st, "%s[...repeated from below...]", detailSep)
-	}
-}
@@ -1,16 +1,41 @@
 package errors
 
-import "fmt"
+import (
+	stderrs "errors"
+	"fmt"
+)
 
 // Wrap wraps an error with a message prefix.
-// A stack trace is retained.
+// A stack trace is retained. When err already has one, other than the one
+// of a package initializer, only the caller is
+// recorded, as a hop of the return trace (see Trace), rather than the
+// frames that are not shared with that stack, so the message layer has a
+// nil stack.
 func Wrap(err error, msg string) error {
-	return With(err, New(msg))
+	return wrapDepth(err, stderrs.New(msg), 1)
 }
 
 // Wrapf wraps an error with a formatted message prefix. A stack
 // trace is also retained. If the format is empty, no prefix is added,
 // but the extra arguments are still processed for reportable strings.
 func Wrapf(err error, format string, args ...interface{}) error {
-	return With(err, New(fmt.Sprintf(format, args...)))
+	return wrapDepth(err, stderrs.New(fmt.Sprintf(format, args...)), 1)
+}
+
+// wrapDepth puts msg in front of err, with the stack from the given call
+// depth, or only its program counter if err already has a stack.
+func wrapDepth(err, msg error, depth int) error {
+	if err == nil {
+		return nil
+	}
+	if hasStack(err) {
+		front := &withStack{cause: msg}
+		if pc := callerPC(depth + 1); pc != 0 {
+			front.returnTrace = []uintptr{pc}
+		}
+		return forward(With(err, front), err)
+	}
+	st, _ := capture(depth+2, GetCapturePolicy())
+
+	return forward(With(err, &withStack{cause: msg, Stack: st}), err)
 }
@@ -0,0 +1,173 @@
+package errors
+
+import (
+	"fmt"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// This file implements the golang.org/x/xerrors Formatter protocol both
+// ways: the wrappers of this package print their stacks as detail for the
+// printers of xerrors, and the FormatError method of other errors is
+// honored when building a Report.
+
+// compiler enforced interface conformance checks
+var (
+	_ xerrors.Formatter = (*withStack)(nil)
+	_ xerrors.Formatter = (*withFields)(nil)
+	_ xerrors.Formatter = (*wrapper)(nil)
+	_ xerrors.Formatter = Forwarding{}
+	_ xerrors.Printer   = (*detailPrinter)(nil)
+)
+
+// FormatError implements xerrors.Formatter. A withStack adds no message
+// of its own. With detail, it prints only its stack, in an entry of its
+// own, and returns its cause, which prints its own detail. Otherwise, and
+// when its cause is not a Formatter, whose message comes with no detail,
+// it prints the message of its cause, so that no entry is empty.
+func (w *withStack) FormatError(p xerrors.Printer) error {
+	if isHop(w) {
+		// The layers added by Trace print as their cause.
+		return formatCause(p, w.cause)
+	}
+	if _, ok := w.cause.(xerrors.Formatter); ok && printsDetail(p) {
+		if p.Detail() {
+			printStack(p, w.Stack, w.hasSkippedFrames)
+		}
+		return w.cause
+	}
+	next := formatCause(p, w.cause)
+	if p.Detail() {
+		printStack(p, w.Stack, w.hasSkippedFrames)
+	}
+
+	return next
+}
+
+// FormatError implements xerrors.Formatter.
+func (w *withFields) FormatError(p xerrors.Printer) error {
+	p.Print(strings.TrimSuffix(formatFields(w.fields), ","))
+	if p.Detail() {
+		printStack(p, w.Stack, w.hasSkippedFrames)
+	}
+
+	return w.cause
+}
+
+// FormatError implements xerrors.Formatter. It prints the front error,
+// with the stack recorded by Wrap, and returns the back one.
+func (s *wrapper) FormatError(p xerrors.Printer) error {
+	p.Print(s.front.Error())
+	if p.Detail() {
+		switch f := s.front.(type) {
+		case *withStack:
+			printStack(p, f.Stack, f.hasSkippedFrames)
+		case *withFields:
+			printStack(p, f.Stack, f.hasSkippedFrames)
+		}
+	}
+
+	return s.back
+}
+
+// FormatError implements xerrors.Formatter, as the FormatError method of
+// Err.
+func (f Forwarding) FormatError(p xerrors.Printer) error {
+	if xf, ok := f.Err.(xerrors.Formatter); ok {
+		return xf.FormatError(p)
+	}
+	p.Print(f.Err.Error())
+
+	return nil
+}
+
+// formatCause prints the first error of err and returns the next one, as
+// the printers of xerrors do for the errors that are not Formatters.
+func formatCause(p xerrors.Printer, err error) error {
+	if f, ok := err.(xerrors.Formatter); ok {
+		return f.FormatError(p)
+	}
+	p.Print(err.Error())
+
+	return nil
+}
+
+// printsDetail reports whether p prints detail, without starting the
+// detail of the current error as p.Detail() does. The printers of xerrors
+// are fmt.States, which print detail for %+v.
+func printsDetail(p xerrors.Printer) bool {
+	st, ok := p.(interface{ Flag(c int) bool })
+	return ok && st.Flag('+')
+}
+
+// printStack prints the frames of s as detail, in the layout of
+// xerrors.Frame.
+func printStack(p xerrors.Printer, s *Stack, elided bool) {
+	for _, frame := range stackFrames(s, elided) {
+		p.Printf("%s\n    %s:%d\n", frame.Function, frame.File, frame.Line)
+	}
+	if elided {
+		p.Print("[...repeated from below...]\n")
+	}
+}
+
+// detailPrinter is the xerrors.Printer given to the FormatError method of
+// the errors of other packages, to collect their detail.
+type detailPrinter struct {
+	msg, detail strings.Builder
+	inDetail    bool
+}
+
+func (p *detailPrinter) out() *strings.Builder {
+	if p.inDetail {
+		return &p.detail
+	}
+
+	return &p.msg
+}
+
+func (p *detailPrinter) Print(args ...any) { _, _ = fmt.Fprint(p.out(), args...) }
+
+func (p *detailPrinter) Printf(format string, args ...any) {
+	_, _ = fmt.Fprintf(p.out(), format, args...)
+}
+
+func (p *detailPrinter) Detail() bool {
+	p.inDetail = true
+	return true
+}
+
+// frameLocation matches the file:line line of a frame printed by
+// xerrors.Frame.
+var frameLocation = regexp.MustCompile(`^(.+):(\d+)$`)
+
+// formatErrorDetail calls the FormatError method of f, and returns what
+// it prints as detail: as frames if it is the location of xerrors.Frame,
+// and as lines otherwise.
+func formatErrorDetail(f xerrors.Formatter) (frames []runtime.Frame, detail []string) {
+	p := &detailPrinter{}
+	_ = f.FormatError(p)
+	var lines []string
+	for _, line := range strings.Split(p.detail.String(), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	for i := 0; i+1 < len(lines); i += 2 {
+		m := frameLocation.FindStringSubmatch(lines[i+1])
+		if m == nil {
+			return nil, lines
+		}
+		line, _ := strconv.Atoi(m[2])
+		frames = append(frames, runtime.Frame{Function: lines[i], File: m[1], Line: line})
+	}
+	if len(lines)%2 != 0 {
+		return nil, lines
+	}
+
+	return frames, nil
+}
@@ -0,0 +1,86 @@
+package errors_test
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"golang.org/x/xerrors"
+
+	"github.com/StevenACoffman/simplerr/errors"
+)
+
+func TestXerrorsPrinter(t *testing.T) {
+	fields := errors.WrapWithFields(io.EOF, errors.Fields{"k": 1})
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+		// hops is the number of errors printed after the first one.
+		hops int
+	}{
+		{name: "New", err: errors.New("boom"), expected: "ctx: boom", hops: 1},
+		{name: "Wrap", err: errors.Wrap(errors.New("boom"), "dial"), expected: "ctx: dial: boom", hops: 2},
+		{
+			name: "WrapWithFields", err: fields,
+			expected: "ctx: Fields: [k:1]: EOF", hops: 2,
+		},
+		{
+			// The stack of WithStack is printed apart from the one of its
+			// cause.
+			name: "WithStack", err: errors.WithStack(fields), expected: "ctx: Fields: [k:1]: EOF", hops: 3,
+		},
+		{name: "Forwarding", err: errors.WithStack(timeoutError{}), expected: "ctx: i/o timeout", hops: 1},
+		{
+			name: "xerrors", err: errors.WithStack(xerrors.Errorf("inner: %w", io.EOF)),
+			expected: "ctx: inner: EOF", hops: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := xerrors.Errorf("ctx: %w", tt.err)
+			if actual := fmt.Sprintf("%v", err); actual != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, actual)
+			}
+			// The stacks are printed as detail.
+			if actual := fmt.Sprintf("%+v", err); !strings.Contains(actual, "errors_test.TestXerrorsPrinter") ||
+				strings.Count(actual, "\n  - ") != tt.hops {
+				t.Errorf("unexpected detail:\n%s", actual)
+			}
+		})
+	}
+}
+
+// detailError prints a detail that is not a location.
+type detailError struct{}
+
+func (detailError) Error() string { return "rejected" }
+
+func (detailError) FormatError(p xerrors.Printer) error {
+	p.Print("rejected")
+	if p.Detail() {
+		p.Print("status: 403\nreason: forbidden")
+	}
+	return nil
+}
+
+func TestXerrorsFormatter(t *testing.T) {
+	err := errors.WithStack(xerrors.Errorf("ctx: %w", detailError{}))
+	report := errors.NewReport(err)
+	if len(report.Layers) != 3 {
+		t.Fatalf("unexpected layers %+v", report.Layers)
+	}
+	frames := report.Layers[1].Frames
+	if len(frames) != 1 || !strings.HasSuffix(frames[0].Function, "errors_test.TestXerrorsFormatter") ||
+		!strings.HasSuffix(frames[0].File, "xerrors_test.go") || frames[0].Line == 0 {
+		t.Errorf("expected the frame of xerrors.Errorf, got %v", frames)
+	}
+	if detail := report.Layers[2].Detail; strings.Join(detail, "|") != "status: 403|reason: forbidden" {
+		t.Errorf("unexpected detail %q", detail)
+	}
+	if actual := fmt.Sprintf("%+v", err); !strings.Contains(actual,
+		"Wraps: (3) rejected\n  -- Detail:\n  | status: 403\n  | reason: forbidden\n") {
+		t.Errorf("expected a detail section in:\n%s", actual)
+	}
+}